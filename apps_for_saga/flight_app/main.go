@@ -3,13 +3,18 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 
 	"github.com/Shopify/sarama"
 	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/config"
+	"github.com/thepabloaguilar/sukuna/gateways/payload_store"
 	"github.com/thepabloaguilar/sukuna/gateways/step_execution"
+	"github.com/thepabloaguilar/sukuna/gateways/step_execution/steppb"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -51,7 +56,12 @@ func main() {
 		}
 	}()
 
-	consume(ctx, producer)
+	claimCheck, err := config.CreateWorkerClaimCheck()
+	if err != nil {
+		log.Fatalf("error creating the payload claim check: %v", err)
+	}
+
+	consume(ctx, producer, claimCheck)
 
 	select {
 	case <-ctx.Done():
@@ -67,13 +77,16 @@ func createProducer() sarama.SyncProducer {
 	return producer
 }
 
-func consume(ctx context.Context, producer sarama.SyncProducer) {
-	consumerGroup, err := sarama.NewConsumerGroup(kafkaBrokers, consumerGroupName, nil)
+func consume(ctx context.Context, producer sarama.SyncProducer, claimCheck payload_store.ClaimCheck) {
+	// Steps sent in aborted transactions, by the exactly-once mode of sukuna, must not be executed
+	config := sarama.NewConfig()
+	config.Consumer.IsolationLevel = sarama.ReadCommitted
+	consumerGroup, err := sarama.NewConsumerGroup(kafkaBrokers, consumerGroupName, config)
 	if err != nil {
 		log.Fatalf("error creating a consumer group: %v", err)
 	}
 
-	consumer := Consumer{Ready: make(chan bool), Producer: producer}
+	consumer := Consumer{ctx: ctx, Ready: make(chan bool), Producer: producer, ClaimCheck: claimCheck}
 	go func() {
 		for {
 			select {
@@ -104,15 +117,25 @@ type Payload struct {
 }
 
 type SagaStepResult struct {
-	SagaName    string    `json:"saga_name"`
-	StepIndex   int       `json:"step_index"`
-	ExecutionID uuid.UUID `json:"execution_id"`
-	Result string `json:"result"`
+	SagaName    string         `json:"saga_name"`
+	StepIndex   int            `json:"step_index"`
+	ExecutionID uuid.UUID      `json:"execution_id"`
+	Result      string         `json:"result"`
+	Error       *SagaStepError `json:"error,omitempty"`
+}
+
+type SagaStepError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Retryable bool   `json:"retryable"`
 }
 
 type Consumer struct {
-	Ready    chan bool
-	Producer sarama.SyncProducer
+	ctx context.Context
+
+	Ready      chan bool
+	Producer   sarama.SyncProducer
+	ClaimCheck payload_store.ClaimCheck
 }
 
 func (c *Consumer) Setup(_ sarama.ConsumerGroupSession) error {
@@ -126,20 +149,26 @@ func (c Consumer) Cleanup(_ sarama.ConsumerGroupSession) error {
 
 func (c Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	for message := range claim.Messages() {
-		step, _ := messageValueToStepToExecute(message.Value)
+		step, _ := messageToStepToExecute(message)
 		log.Printf("Saga Execution received: %s\n", step.ExecutionID)
 
 		if step.IsCompensation {
 			log.Println("compensated")
 
-			if err := c.sendCompensated(step); err != nil {
+			if err := c.sendCompensated(message, step); err != nil {
 				log.Printf("error compensanting: %v", err)
 			}
 			continue
 		}
 
+		stepPayload, err := step_execution.ResolvePayload(c.ctx, c.ClaimCheck, step)
+		if err != nil {
+			log.Printf("error resolving payload: %v", err)
+			continue
+		}
+
 		var payload Payload
-		if err := json.Unmarshal(step.Payload, &payload); err != nil {
+		if err := json.Unmarshal(stepPayload, &payload); err != nil {
 			log.Printf("error unmarshaling payload: %v", err)
 			continue
 		}
@@ -147,14 +176,17 @@ func (c Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama
 		if payload.FlightCompanyName == "LATAM" {
 			log.Println("Sending success")
 
-			err := c.sendSuccess(step)
+			err := c.sendSuccess(message, step)
 			if err != nil {
 				log.Printf("An error occurred: %v\n", err)
 			}
 		} else {
 			log.Println("Sending failure")
 
-			err := c.sendError(step)
+			err := c.sendError(message, step, SagaStepError{
+				Code:    "FLIGHT_UNAVAILABLE",
+				Message: fmt.Sprintf("flight company %s has no seats available", payload.FlightCompanyName),
+			})
 			if err != nil {
 				log.Printf("An error occurred: %v\n", err)
 			}
@@ -165,53 +197,116 @@ func (c Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama
 	return nil
 }
 
-func (c Consumer) sendSuccess(step step_execution.StepToExecute) error {
+func (c Consumer) sendSuccess(message *sarama.ConsumerMessage, step step_execution.StepToExecute) error {
 	result := SagaStepResult{
 		SagaName:    step.SagaName,
 		StepIndex:   step.StepIndex,
 		ExecutionID: step.ExecutionID,
 		Result:      "success",
 	}
-	return c.sendResult(result)
+	return c.sendResult(message, result)
 }
 
-func (c Consumer) sendError(step step_execution.StepToExecute) error {
+func (c Consumer) sendError(
+	message *sarama.ConsumerMessage,
+	step step_execution.StepToExecute,
+	stepError SagaStepError,
+) error {
 	result := SagaStepResult{
 		SagaName:    step.SagaName,
 		StepIndex:   step.StepIndex,
 		ExecutionID: step.ExecutionID,
 		Result:      "error",
+		Error:       &stepError,
 	}
-	return c.sendResult(result)
+	return c.sendResult(message, result)
 }
 
-func (c Consumer) sendCompensated(step step_execution.StepToExecute) error {
+func (c Consumer) sendCompensated(message *sarama.ConsumerMessage, step step_execution.StepToExecute) error {
 	result := SagaStepResult{
 		SagaName:    step.SagaName,
 		StepIndex:   step.StepIndex,
 		ExecutionID: step.ExecutionID,
 		Result:      "compensated",
 	}
-	return c.sendResult(result)
+	return c.sendResult(message, result)
 }
 
-
-func (c Consumer) sendResult(result SagaStepResult) error {
-	marshaledResult, err := json.Marshal(result)
+// sendResult sends back the key and the headers of the step message, so the result is correlated to it
+func (c Consumer) sendResult(message *sarama.ConsumerMessage, result SagaStepResult) error {
+	_, stepContentType, err := messageData(message)
+	if err != nil {
+		return err
+	}
+	marshaledResult, contentType, err := marshalResult(stepContentType, result)
 	if err != nil {
 		return err
 	}
 
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers)+1)
+	for _, header := range message.Headers {
+		// The result isn't a CloudEvent, so the CloudEvents attributes of the step aren't sent back
+		if step_execution.IsCloudEventsHeader(header.Key) {
+			continue
+		}
+		headers = append(headers, *header)
+	}
+	headers = append(headers, sarama.RecordHeader{Key: []byte("content-type"), Value: []byte(contentType)})
+
 	_, _, err = c.Producer.SendMessage(&sarama.ProducerMessage{
-		Topic: resultTopic,
-		Value: sarama.ByteEncoder(marshaledResult),
+		Topic:   resultTopic,
+		Key:     sarama.ByteEncoder(message.Key),
+		Value:   sarama.ByteEncoder(marshaledResult),
+		Headers: headers,
 	})
 
 	return err
 }
 
-func messageValueToStepToExecute(value []byte) (step_execution.StepToExecute, error) {
-	var stepToExecute step_execution.StepToExecute
-	err := json.Unmarshal(value, &stepToExecute)
-	return stepToExecute, err
+// messageData returns the step message and its content type, unwrapping the CloudEvents
+func messageData(message *sarama.ConsumerMessage) ([]byte, string, error) {
+	event, isCloudEvent, err := step_execution.DecodeKafkaCloudEvent(message)
+	if err != nil {
+		return nil, "", err
+	}
+	if isCloudEvent {
+		return event.Data, event.DataContentType, nil
+	}
+
+	return message.Value, step_execution.ReadContentType(message.Headers), nil
+}
+
+// messageToStepToExecute reads the step in JSON or protobuf, plain or wrapped in CloudEvents
+func messageToStepToExecute(message *sarama.ConsumerMessage) (step_execution.StepToExecute, error) {
+	value, contentType, err := messageData(message)
+	if err != nil {
+		return step_execution.StepToExecute{}, err
+	}
+
+	return step_execution.UnmarshalStepToExecute(contentType, value)
+}
+
+// marshalResult encodes the result like the step was encoded, returning its content type
+func marshalResult(stepContentType string, result SagaStepResult) ([]byte, string, error) {
+	if !step_execution.IsProtobufContentType(stepContentType) {
+		value, err := json.Marshal(result)
+		return value, step_execution.ContentTypeJSON, err
+	}
+
+	message := &steppb.SagaStepResult{
+		SagaName:    result.SagaName,
+		StepIndex:   int32(result.StepIndex),
+		ExecutionId: result.ExecutionID.String(),
+		Result:      step_execution.StepResultToProto(result.Result),
+	}
+	if result.Error != nil {
+		message.Error = &steppb.StepError{
+			Code:      result.Error.Code,
+			Message:   result.Error.Message,
+			Retryable: result.Error.Retryable,
+		}
+	}
+	value, err := proto.Marshal(message)
+
+	return value, step_execution.ProtobufContentType(message), err
 }
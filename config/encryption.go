@@ -0,0 +1,24 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/thepabloaguilar/sukuna/gateways/encryption"
+)
+
+// CreateKeyring reads the encryption keys from `SUKUNA_ENCRYPTION_KEYS` (`key-id:base64-key,...`)
+// and the key used to encrypt new values from `SUKUNA_ENCRYPTION_KEY_ID`.
+func CreateKeyring() (encryption.Keyring, error) {
+	keys, err := encryption.ParseKeys(os.Getenv("SUKUNA_ENCRYPTION_KEYS"))
+	if err != nil {
+		return encryption.Keyring{}, fmt.Errorf("error parsing encryption keys: %w", err)
+	}
+
+	keyring, err := encryption.NewKeyring(os.Getenv("SUKUNA_ENCRYPTION_KEY_ID"), keys)
+	if err != nil {
+		return encryption.Keyring{}, fmt.Errorf("error creating encryption keyring: %w", err)
+	}
+
+	return keyring, nil
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/thepabloaguilar/sukuna/gateways/step_execution"
+)
+
+// KafkaBrokers reads the kafka brokers from `SUKUNA_KAFKA_BROKERS`, comma separated. Without them kafka isn't
+// available and nil is returned.
+func KafkaBrokers() []string {
+	value := os.Getenv("SUKUNA_KAFKA_BROKERS")
+	if value == "" {
+		return nil
+	}
+
+	var brokers []string
+	for _, broker := range strings.Split(value, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokers = append(brokers, broker)
+		}
+	}
+
+	return brokers
+}
+
+// CreateKafkaProducer creates the producer of the kafka steps, along with their config, when `SUKUNA_KAFKA_BROKERS`
+// is set. Without it the kafka transport isn't available and the producer is nil.
+func CreateKafkaProducer() (sarama.SyncProducer, step_execution.KafkaConfig, error) {
+	brokers := KafkaBrokers()
+	if brokers == nil {
+		return nil, step_execution.KafkaConfig{}, nil
+	}
+
+	kafkaConfig, err := CreateKafkaConfig(brokers)
+	if err != nil {
+		return nil, step_execution.KafkaConfig{}, fmt.Errorf("error reading the kafka config: %w", err)
+	}
+
+	producer, err := sarama.NewSyncProducer(brokers, nil)
+	if err != nil {
+		return nil, step_execution.KafkaConfig{}, fmt.Errorf("error creating the producer: %w", err)
+	}
+
+	return producer, kafkaConfig, nil
+}
+
+// CreateKafkaConfig reads how the kafka steps are sent. `SUKUNA_CLOUDEVENTS_MODE` wraps them in CloudEvents,
+// `structured` or `binary`, and `SUKUNA_KAFKA_TOPIC_PREFIX`, `SUKUNA_KAFKA_TOPIC_ENVIRONMENT` and
+// `SUKUNA_KAFKA_COMPENSATION_TOPICS` name their topics. With `SUKUNA_KAFKA_PROVISION_TOPICS` the topics are created
+// along with the sagas, with `SUKUNA_KAFKA_TOPIC_PARTITIONS` and `SUKUNA_KAFKA_TOPIC_REPLICATION_FACTOR`, 1 by default.
+func CreateKafkaConfig(brokers []string) (step_execution.KafkaConfig, error) {
+	cloudEventsMode, err := step_execution.ParseCloudEventsMode(os.Getenv("SUKUNA_CLOUDEVENTS_MODE"))
+	if err != nil {
+		return step_execution.KafkaConfig{}, err
+	}
+
+	kafkaConfig := step_execution.KafkaConfig{
+		CloudEventsMode: cloudEventsMode,
+		TopicNaming: step_execution.TopicNaming{
+			Prefix:                os.Getenv("SUKUNA_KAFKA_TOPIC_PREFIX"),
+			Environment:           os.Getenv("SUKUNA_KAFKA_TOPIC_ENVIRONMENT"),
+			SeparateCompensations: os.Getenv("SUKUNA_KAFKA_COMPENSATION_TOPICS") == "true",
+		},
+	}
+	if os.Getenv("SUKUNA_KAFKA_PROVISION_TOPICS") != "true" {
+		return kafkaConfig, nil
+	}
+
+	partitions, replicationFactor := 1, 1
+	if value := os.Getenv("SUKUNA_KAFKA_TOPIC_PARTITIONS"); value != "" {
+		if partitions, err = strconv.Atoi(value); err != nil {
+			return step_execution.KafkaConfig{}, fmt.Errorf("error parsing topic partitions: %w", err)
+		}
+	}
+	if value := os.Getenv("SUKUNA_KAFKA_TOPIC_REPLICATION_FACTOR"); value != "" {
+		if replicationFactor, err = strconv.Atoi(value); err != nil {
+			return step_execution.KafkaConfig{}, fmt.Errorf("error parsing topic replication factor: %w", err)
+		}
+	}
+
+	admin, err := sarama.NewClusterAdmin(brokers, nil)
+	if err != nil {
+		return step_execution.KafkaConfig{}, fmt.Errorf("error creating the kafka cluster admin: %w", err)
+	}
+	kafkaConfig.TopicProvisioner = step_execution.NewTopicProvisioner(admin, int32(partitions), int16(replicationFactor))
+
+	return kafkaConfig, nil
+}
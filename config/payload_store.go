@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/thepabloaguilar/sukuna/gateways/encryption"
+	"github.com/thepabloaguilar/sukuna/gateways/payload_store"
+)
+
+// CreateClaimCheck reads the payload store from `SUKUNA_PAYLOAD_STORE_URL` and the size, in bytes, from which
+// payloads are moved to it from `SUKUNA_PAYLOAD_THRESHOLD`. Without a store every payload is kept inline.
+func CreateClaimCheck(keyring encryption.Keyring) (payload_store.ClaimCheck, error) {
+	storeURL := os.Getenv("SUKUNA_PAYLOAD_STORE_URL")
+	if storeURL == "" {
+		return payload_store.ClaimCheck{}, nil
+	}
+
+	threshold, err := strconv.Atoi(os.Getenv("SUKUNA_PAYLOAD_THRESHOLD"))
+	if err != nil {
+		return payload_store.ClaimCheck{}, fmt.Errorf("error parsing payload threshold: %w", err)
+	}
+
+	store, err := payload_store.Open(storeURL)
+	if err != nil {
+		return payload_store.ClaimCheck{}, err
+	}
+
+	return payload_store.NewClaimCheck(store, threshold, keyring), nil
+}
+
+// CreateWorkerClaimCheck uses the same store and keys as sukuna, from `SUKUNA_PAYLOAD_STORE_URL` and
+// `SUKUNA_ENCRYPTION_KEYS`, for workers to resolve big payloads, workers never offload their results.
+func CreateWorkerClaimCheck() (payload_store.ClaimCheck, error) {
+	storeURL := os.Getenv("SUKUNA_PAYLOAD_STORE_URL")
+	if storeURL == "" {
+		return payload_store.ClaimCheck{}, nil
+	}
+
+	keyring, err := CreateKeyring()
+	if err != nil {
+		return payload_store.ClaimCheck{}, err
+	}
+
+	store, err := payload_store.Open(storeURL)
+	if err != nil {
+		return payload_store.ClaimCheck{}, err
+	}
+
+	return payload_store.NewClaimCheck(store, 0, keyring), nil
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/Shopify/sarama"
+	"github.com/go-redis/redis/v8"
+	"github.com/nats-io/nats.go"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/core/tasks"
+	"github.com/thepabloaguilar/sukuna/gateways/payload_store"
+	"github.com/thepabloaguilar/sukuna/gateways/step_execution"
+)
+
+// SagaServiceOptions are the connections an entrypoint shares with the saga service, the transports without them
+// are created from the environment.
+type SagaServiceOptions struct {
+	// Context stops the background work of the transports when done, like the requests of the http steps, it's
+	// context.Background() when nil
+	Context context.Context
+	// KafkaProducer sends the kafka steps, the kafka transport is only available with it
+	KafkaProducer sarama.SyncProducer
+	KafkaConfig   step_execution.KafkaConfig
+	// JetStream sends the nats steps, without it the nats transport is available when `SUKUNA_NATS_URL` is set
+	JetStream nats.JetStreamContext
+	// RedisClient sends the redis steps, without it the redis transport is available when `SUKUNA_REDIS_URL` is set
+	RedisClient *redis.Client
+	// HandleResults wraps the handling of the results of the http steps, e.g. in a transaction
+	HandleResults func(handle func() error) error
+}
+
+// CreateSagaService creates the saga service along with the gateway of every available transport. The http and the
+// postgres transports are always available, RabbitMQ only when `SUKUNA_AMQP_URL` is set.
+func CreateSagaService(
+	repository sagas.SagaRepository,
+	taskRepository tasks.Repository,
+	claimCheck payload_store.ClaimCheck,
+	options SagaServiceOptions,
+) (sagas.Service, error) {
+	// The http gateway hands the results to the service, which is only created after its gateways
+	var sagaService sagas.Service
+	handleResult := func(ctx context.Context, result sagas.StepResultVO) error {
+		handle := func() error {
+			return sagaService.HandleStepResult(ctx, result)
+		}
+		if options.HandleResults == nil {
+			return handle()
+		}
+		return options.HandleResults(handle)
+	}
+
+	ctx := options.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	transports := map[entities.StepTransport]sagas.StepExecutionGateway{
+		entities.StepTransportHTTP: step_execution.NewHTTPGateway(
+			ctx, http.DefaultClient, handleResult, repository.IncrementSagaStepExecutionAttempt,
+		),
+		entities.StepTransportPostgres: step_execution.NewTaskGateway(taskRepository, claimCheck),
+	}
+	if options.KafkaProducer != nil {
+		transports[entities.StepTransportKafka] = step_execution.NewGateway(
+			options.KafkaProducer, claimCheck, options.KafkaConfig,
+		)
+	}
+
+	amqpGateway, err := createAMQPGateway(claimCheck)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the amqp gateway: %w", err)
+	}
+	if amqpGateway != nil {
+		transports[entities.StepTransportAMQP] = amqpGateway
+	}
+
+	jetStream := options.JetStream
+	if natsURL := os.Getenv("SUKUNA_NATS_URL"); jetStream == nil && natsURL != "" {
+		if jetStream, err = CreateJetStream(natsURL); err != nil {
+			return nil, err
+		}
+	}
+	if jetStream != nil {
+		natsGateway, err := step_execution.NewNATSGateway(jetStream, claimCheck)
+		if err != nil {
+			return nil, fmt.Errorf("error creating the nats gateway: %w", err)
+		}
+		transports[entities.StepTransportNATS] = natsGateway
+	}
+
+	redisClient := options.RedisClient
+	if redisURL := os.Getenv("SUKUNA_REDIS_URL"); redisClient == nil && redisURL != "" {
+		if redisClient, err = CreateRedisClient(redisURL); err != nil {
+			return nil, err
+		}
+	}
+	if redisClient != nil {
+		transports[entities.StepTransportRedis] = step_execution.NewRedisGateway(redisClient, claimCheck)
+	}
+
+	sagaService = sagas.NewService(repository, step_execution.NewRegistry(transports))
+	return sagaService, nil
+}
+
+// createAMQPGateway connects to the RabbitMQ at `SUKUNA_AMQP_URL`, without it the amqp transport isn't available.
+func createAMQPGateway(claimCheck payload_store.ClaimCheck) (sagas.StepExecutionGateway, error) {
+	amqpURL := os.Getenv("SUKUNA_AMQP_URL")
+	if amqpURL == "" {
+		return nil, nil
+	}
+
+	return step_execution.NewAMQPGateway(amqpURL, claimCheck)
+}
+
+// CreateJetStream connects to the NATS server at url.
+func CreateJetStream(url string) (nats.JetStreamContext, error) {
+	connection, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to nats: %w", err)
+	}
+	jetStream, err := connection.JetStream()
+	if err != nil {
+		connection.Close()
+		return nil, fmt.Errorf("error getting the jetstream context: %w", err)
+	}
+
+	return jetStream, nil
+}
+
+// CreateRedisClient creates the client of the Redis at url, in the `redis://` form.
+func CreateRedisClient(url string) (*redis.Client, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing redis url: %w", err)
+	}
+
+	return redis.NewClient(options), nil
+}
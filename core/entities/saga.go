@@ -10,22 +10,37 @@ type Saga struct {
 	SagaID        uuid.UUID
 	Name          string
 	FormattedName string
+	Description   string
 	Payload       []byte
+	RetentionDays int
 	CreatedAt     time.Time
+	DeprecatedAt  *time.Time
+}
+
+func (s Saga) IsDeprecated() bool {
+	return s.DeprecatedAt != nil
 }
 
 type SagaStep struct {
-	StepID uuid.UUID
-	SagaID uuid.UUID
-	Index  int
-	Name   string
+	StepID       uuid.UUID
+	SagaID       uuid.UUID
+	Index        int
+	Name         string
+	InputMapping []byte
+	// CompensateOnFailure also compensates the step when it fails, for steps that may partially succeed
+	CompensateOnFailure bool
+	Transport           StepTransport
+	// TransportConfig is a JSON document read by the gateway of the transport, e.g. the URL of http steps
+	TransportConfig []byte
 }
 
 type SagaExecution struct {
 	SagaExecutionID uuid.UUID
 	SagaID          uuid.UUID
 	Payload         []byte
+	Status          SagaExecutionStatus
 	CreatedAt       time.Time
+	FinishedAt      *time.Time
 }
 
 type StepExecution struct {
@@ -34,4 +49,29 @@ type StepExecution struct {
 	Index           int
 	Name            string
 	Status          StepExecutionStatus
+	InputMapping    []byte
+	Output          []byte
+	// CompensateOnFailure is copied from the saga step when the execution is created
+	CompensateOnFailure bool
+	Transport           StepTransport
+	TransportConfig     []byte
+	StartedAt           *time.Time
+	FinishedAt          *time.Time
+	Error               *StepError
+	// Failed is kept once the step fails, a step compensating itself moves on to the compensation statuses
+	Failed bool
+	// Attempt counts the times the step was sent, its compensations included
+	Attempt int
+}
+
+// StepError is what the worker reported when the step failed, Details is an arbitrary JSON document
+type StepError struct {
+	Code      string
+	Message   string
+	Retryable bool
+	Details   []byte
+}
+
+func (e StepError) Error() string {
+	return e.Code + ": " + e.Message
 }
@@ -0,0 +1,10 @@
+package entities
+
+type SagaExecutionStatus string
+
+const (
+	SagaExecutionRunning      SagaExecutionStatus = "running"
+	SagaExecutionCompensating SagaExecutionStatus = "compensating"
+	SagaExecutionSucceeded    SagaExecutionStatus = "succeeded"
+	SagaExecutionFailed       SagaExecutionStatus = "failed"
+)
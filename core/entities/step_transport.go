@@ -0,0 +1,14 @@
+package entities
+
+// StepTransport is how the step command reaches the worker and how its result comes back
+type StepTransport string
+
+const (
+	StepTransportKafka StepTransport = "kafka"
+	StepTransportHTTP  StepTransport = "http"
+	StepTransportAMQP  StepTransport = "amqp"
+	StepTransportNATS  StepTransport = "nats"
+	StepTransportRedis StepTransport = "redis"
+	// StepTransportPostgres keeps the steps in the tasks table, workers pull them from the API
+	StepTransportPostgres StepTransport = "postgres"
+)
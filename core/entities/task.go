@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Task is a step waiting for a worker to pull it, it's deleted once the worker reports the step result.
+type Task struct {
+	TaskID uuid.UUID
+	// Token identifies the current lease, leasing the task again gives it a new token
+	Token           uuid.UUID
+	Queue           string
+	SagaName        string
+	SagaExecutionID uuid.UUID
+	StepIndex       int
+	// Step is the same JSON document the other transports send to the workers
+	Step        []byte
+	LeasedUntil *time.Time
+	CreatedAt   time.Time
+}
@@ -0,0 +1,32 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+)
+
+type Repository interface {
+	GetSaga(ctx context.Context, sagaID uuid.UUID) (entities.Saga, error)
+	GetSagasWithRetention(ctx context.Context) ([]entities.Saga, error)
+
+	GetExpiredSagaExecutions(
+		ctx context.Context,
+		sagaID uuid.UUID,
+		createdBefore time.Time,
+		limit int,
+	) ([]entities.SagaExecution, error)
+	GetSagaStepsExecutionByExecutionID(ctx context.Context, executionID uuid.UUID) ([]entities.StepExecution, error)
+
+	// ArchiveSagaExecutions deletes the executions and keeps where they were archived
+	ArchiveSagaExecutions(ctx context.Context, sagaID uuid.UUID, executionIDs []uuid.UUID, location string) error
+	GetArchivedExecutionLocation(ctx context.Context, executionID uuid.UUID) (string, error)
+}
+
+type ExecutionArchive interface {
+	Write(ctx context.Context, sagaID uuid.UUID, executions []sagas.SagaExecutionVO) (string, error)
+	Read(ctx context.Context, location string, executionID uuid.UUID) (sagas.SagaExecutionVO, error)
+}
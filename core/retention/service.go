@@ -0,0 +1,110 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+)
+
+const archiveBatchSize = 500
+
+var ErrArchivedExecutionNotFound = errors.New("archived execution not found")
+
+type Service interface {
+	ArchiveExpiredExecutions(ctx context.Context) (int, error)
+	GetArchivedExecution(ctx context.Context, executionID uuid.UUID) (sagas.SagaExecutionVO, error)
+}
+
+type service struct {
+	repository Repository
+	archive    ExecutionArchive
+}
+
+func NewService(repository Repository, archive ExecutionArchive) Service {
+	return service{
+		repository: repository,
+		archive:    archive,
+	}
+}
+
+// ArchiveExpiredExecutions moves the finished executions older than their saga retention to the archive.
+func (svc service) ArchiveExpiredExecutions(ctx context.Context) (int, error) {
+	sagasWithRetention, err := svc.repository.GetSagasWithRetention(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error getting sagas with retention: %w", err)
+	}
+
+	archived := 0
+	for _, saga := range sagasWithRetention {
+		count, err := svc.archiveSagaExecutions(ctx, saga)
+		archived += count
+		if err != nil {
+			return archived, fmt.Errorf("error archiving executions of saga %s: %w", saga.SagaID, err)
+		}
+	}
+
+	return archived, nil
+}
+
+func (svc service) archiveSagaExecutions(ctx context.Context, saga entities.Saga) (int, error) {
+	sensitiveFields, err := sagas.SensitiveFields(saga.Payload)
+	if err != nil {
+		return 0, err
+	}
+
+	createdBefore := time.Now().UTC().AddDate(0, 0, -saga.RetentionDays)
+	archived := 0
+	for {
+		executions, err := svc.repository.GetExpiredSagaExecutions(ctx, saga.SagaID, createdBefore, archiveBatchSize)
+		if err != nil {
+			return archived, err
+		}
+		if len(executions) == 0 {
+			return archived, nil
+		}
+
+		vos := make([]sagas.SagaExecutionVO, 0, len(executions))
+		executionIDs := make([]uuid.UUID, 0, len(executions))
+		for _, execution := range executions {
+			steps, err := svc.repository.GetSagaStepsExecutionByExecutionID(ctx, execution.SagaExecutionID)
+			if err != nil {
+				return archived, err
+			}
+
+			vos = append(vos, sagas.SagaExecutionVO{
+				SagaExecution:   execution,
+				Steps:           steps,
+				SensitiveFields: sensitiveFields,
+			})
+			executionIDs = append(executionIDs, execution.SagaExecutionID)
+		}
+
+		location, err := svc.archive.Write(ctx, saga.SagaID, vos)
+		if err != nil {
+			return archived, err
+		}
+
+		err = svc.repository.ArchiveSagaExecutions(ctx, saga.SagaID, executionIDs, location)
+		if err != nil {
+			return archived, err
+		}
+
+		archived += len(executionIDs)
+		log.Printf("%d executions of saga %s archived at %s", len(executionIDs), saga.SagaID, location)
+	}
+}
+
+func (svc service) GetArchivedExecution(ctx context.Context, executionID uuid.UUID) (sagas.SagaExecutionVO, error) {
+	location, err := svc.repository.GetArchivedExecutionLocation(ctx, executionID)
+	if err != nil {
+		return sagas.SagaExecutionVO{}, err
+	}
+
+	return svc.archive.Read(ctx, location, executionID)
+}
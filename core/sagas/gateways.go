@@ -2,6 +2,8 @@ package sagas
 
 import (
 	"context"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/thepabloaguilar/sukuna/core/entities"
 )
@@ -10,30 +12,70 @@ type SagaRepository interface {
 	// Sagas
 
 	GetSaga(ctx context.Context, sagaID uuid.UUID) (entities.Saga, error)
+	// ListSagas returns the sagas without their payloads
+	ListSagas(ctx context.Context, filter SagaFilter) ([]entities.Saga, error)
 	CreateSaga(ctx context.Context, saga entities.Saga) (entities.Saga, error)
+	UpdateSaga(ctx context.Context, saga entities.Saga) (entities.Saga, error)
+	DeprecateSaga(ctx context.Context, sagaID uuid.UUID) (entities.Saga, error)
+	DeleteSaga(ctx context.Context, sagaID uuid.UUID) error
 
 	// Saga Steps
 
 	GetSagaStepsBySagaID(ctx context.Context, sagaID uuid.UUID) ([]entities.SagaStep, error)
+	GetSagaStepsBySagaIDs(ctx context.Context, sagaIDs []uuid.UUID) ([]entities.SagaStep, error)
 	CreateSagaSteps(ctx context.Context, steps []entities.SagaStep) ([]entities.SagaStep, error)
 
 	// Saga Execution
 
 	GetSagaExecution(ctx context.Context, executionID uuid.UUID) (entities.SagaExecution, error)
 	CreateSagaExecution(ctx context.Context, execution entities.SagaExecution) (entities.SagaExecution, error)
+	// ListSagaExecutions returns the executions without their payloads
+	ListSagaExecutions(ctx context.Context, filter SagaExecutionFilter) ([]entities.SagaExecution, error)
+	SetSagaExecutionStatus(ctx context.Context, status entities.SagaExecutionStatus, executionID uuid.UUID) error
 
 	// Saga Steps Execution
 
 	GetSagaStepsExecutionByExecutionID(ctx context.Context, executionID uuid.UUID) ([]entities.StepExecution, error)
+	GetSagaStepsExecutionByExecutionIDs(ctx context.Context, executionIDs []uuid.UUID) ([]entities.StepExecution, error)
 	CreateSagaStepsExecution(ctx context.Context, steps []entities.StepExecution) ([]entities.StepExecution, error)
 	SetSagaStepExecutionStatus(ctx context.Context, status entities.StepExecutionStatus, index int, executionID uuid.UUID) error
+	SetSagaStepExecutionOutput(ctx context.Context, output []byte, index int, executionID uuid.UUID) error
+	SetSagaStepExecutionError(ctx context.Context, stepError entities.StepError, index int, executionID uuid.UUID) error
+	// IncrementSagaStepExecutionAttempt counts a new send of the step, returning its attempt
+	IncrementSagaStepExecutionAttempt(ctx context.Context, index int, executionID uuid.UUID) (int, error)
+
+	// Statistics
+
+	GetSagaExecutionStatistics(ctx context.Context, sagaID uuid.UUID, from, to time.Time) (ExecutionStatistics, error)
+	GetSagaStepStatistics(ctx context.Context, sagaID uuid.UUID, from, to time.Time) ([]StepStatistics, error)
 }
 
 type StepExecutionGateway interface {
+	SupportsTransport(transport entities.StepTransport) bool
 	SendStepToExecute(
 		sagaName string,
 		sagaExecution entities.SagaExecution,
 		sagaStep entities.StepExecution,
+		stepsExecution []entities.StepExecution,
 		isCompensation bool,
 	) error
 }
+
+// StepProvisioner is implemented by the gateways that create resources for the steps, like the Kafka topics,
+// which are provisioned when the saga is created.
+type StepProvisioner interface {
+	ProvisionSteps(ctx context.Context, sagaName string, steps []entities.SagaStep) error
+}
+
+// StepValidator is implemented by the gateways reading a transport config, it's validated when the saga is created
+// instead of failing when the step is sent.
+type StepValidator interface {
+	ValidateStep(step entities.SagaStep) error
+}
+
+// PayloadEncrypter is implemented by the repositories encrypting the sensitive fields of the payloads. Sagas with
+// sensitive fields are refused without a key to encrypt them, and so are documents with values looking encrypted.
+type PayloadEncrypter interface {
+	CanEncrypt() bool
+	HasEncryptedValues(document []byte) (bool, error)
+}
@@ -0,0 +1,84 @@
+package sagas
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/jsonpointer"
+)
+
+const (
+	referencePrefix = "{{"
+	referenceSuffix = "}}"
+)
+
+// ParseInputReference returns the JSON pointer of an input mapping value in the form `{{ /json/pointer }}`.
+func ParseInputReference(value string) (string, bool) {
+	if !strings.HasPrefix(value, referencePrefix) || !strings.HasSuffix(value, referenceSuffix) {
+		return "", false
+	}
+
+	reference := strings.TrimSuffix(strings.TrimPrefix(value, referencePrefix), referenceSuffix)
+	return strings.TrimSpace(reference), true
+}
+
+// validateInputMapping checks every reference of a step mapping points either to the execution payload or to the
+// output of one of the previous steps, so a typo is refused when the saga is created instead of when it runs.
+func validateInputMapping(mapping []byte, previousSteps map[string]bool) error {
+	if len(mapping) == 0 {
+		return nil
+	}
+
+	var template interface{}
+	if err := json.Unmarshal(mapping, &template); err != nil {
+		return ErrInvalidInputMapping
+	}
+
+	return validateTemplate(template, previousSteps)
+}
+
+func validateTemplate(template interface{}, previousSteps map[string]bool) error {
+	switch value := template.(type) {
+	case map[string]interface{}:
+		for _, item := range value {
+			if err := validateTemplate(item, previousSteps); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if err := validateTemplate(item, previousSteps); err != nil {
+				return err
+			}
+		}
+	case string:
+		reference, ok := ParseInputReference(value)
+		if !ok {
+			return nil
+		}
+		return validateReference(reference, previousSteps)
+	}
+
+	return nil
+}
+
+func validateReference(reference string, previousSteps map[string]bool) error {
+	pointer, err := jsonpointer.Parse(reference)
+	if err != nil {
+		return fmt.Errorf("%w: reference %q: %s", ErrInvalidInputMapping, reference, err)
+	}
+
+	switch {
+	case len(pointer) > 0 && pointer[0] == "payload":
+		return nil
+	case len(pointer) > 1 && pointer[0] == "steps" && previousSteps[pointer[1]]:
+		return nil
+	case len(pointer) > 1 && pointer[0] == "steps":
+		return fmt.Errorf("%w: reference %q: %s is not a previous step", ErrInvalidInputMapping, reference, pointer[1])
+	default:
+		return fmt.Errorf(
+			"%w: reference %q must start with /payload or /steps/<step name>", ErrInvalidInputMapping, reference,
+		)
+	}
+}
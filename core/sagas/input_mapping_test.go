@@ -0,0 +1,63 @@
+package sagas
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseInputReference(t *testing.T) {
+	tests := []struct {
+		value     string
+		reference string
+		ok        bool
+	}{
+		{value: "{{ /payload/customer }}", reference: "/payload/customer", ok: true},
+		{value: "{{/steps/hotel/booking_id}}", reference: "/steps/hotel/booking_id", ok: true},
+		{value: "/payload/customer"},
+		{value: "{{ /payload/customer"},
+		{value: "hotel {{ /payload/customer }}"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			reference, ok := ParseInputReference(test.value)
+
+			if ok != test.ok || reference != test.reference {
+				t.Fatalf("expected (%q, %v), got (%q, %v)", test.reference, test.ok, reference, ok)
+			}
+		})
+	}
+}
+
+func TestValidateInputMapping(t *testing.T) {
+	previousSteps := map[string]bool{"hotel": true}
+	tests := []struct {
+		name    string
+		mapping string
+		valid   bool
+	}{
+		{name: "no mapping", mapping: "", valid: true},
+		{name: "payload reference", mapping: `{"customer": "{{ /payload/customer }}"}`, valid: true},
+		{name: "previous step reference", mapping: `{"hotel": "{{ /steps/hotel/booking_id }}"}`, valid: true},
+		{name: "nested references", mapping: `{"items": [{"hotel": "{{ /steps/hotel }}"}]}`, valid: true},
+		{name: "constant values", mapping: `{"currency": "EUR", "amount": 10, "paid": false}`, valid: true},
+		{name: "later step reference", mapping: `{"seat": "{{ /steps/flight/seat }}"}`},
+		{name: "step without name", mapping: `{"steps": "{{ /steps }}"}`},
+		{name: "unknown root", mapping: `{"customer": "{{ /execution/customer }}"}`},
+		{name: "invalid pointer", mapping: `{"customer": "{{ payload/customer }}"}`},
+		{name: "invalid JSON", mapping: `{"customer":`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateInputMapping([]byte(test.mapping), previousSteps)
+
+			if test.valid && err != nil {
+				t.Fatalf("expected the mapping to be valid, got %v", err)
+			}
+			if !test.valid && !errors.Is(err, ErrInvalidInputMapping) {
+				t.Fatalf("expected ErrInvalidInputMapping, got %v", err)
+			}
+		})
+	}
+}
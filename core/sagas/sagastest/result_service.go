@@ -0,0 +1,47 @@
+// Package sagastest has the saga service doubles shared by the tests of the result consumers.
+package sagastest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+)
+
+// ResultService stands for the saga service, only handling the results. It records every result it receives and
+// fails them with the error set, it's safe to use from the consumers goroutines.
+type ResultService struct {
+	sagas.Service
+
+	mutex   sync.Mutex
+	err     error
+	results []sagas.StepResultVO
+}
+
+func NewResultService(err error) *ResultService {
+	return &ResultService{err: err}
+}
+
+func (s *ResultService) HandleStepResult(_ context.Context, result sagas.StepResultVO) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.results = append(s.results, result)
+	return s.err
+}
+
+// Fail makes the next results fail with err, nil to handle them.
+func (s *ResultService) Fail(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.err = err
+}
+
+// Results returns the results received so far, in order.
+func (s *ResultService) Results() []sagas.StepResultVO {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]sagas.StepResultVO{}, s.results...)
+}
@@ -0,0 +1,138 @@
+package sagas
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	sensitiveKeyword = "sensitive"
+	anyArrayItem     = "*"
+	redactedValue    = "[REDACTED]"
+)
+
+// SensitiveFields returns the JSON pointers of every property marked with `"sensitive": true` in the saga schema.
+// Array items are represented by the `*` token, e.g. `/cards/*/number`.
+func SensitiveFields(schema []byte) ([]string, error) {
+	var document map[string]interface{}
+	if err := json.Unmarshal(schema, &document); err != nil {
+		return nil, ErrInvalidJSONSchema
+	}
+
+	fields := make([]string, 0)
+	collectSensitiveFields(document, "", &fields)
+	sort.Strings(fields)
+
+	return fields, nil
+}
+
+func collectSensitiveFields(schema map[string]interface{}, path string, fields *[]string) {
+	if sensitive, _ := schema[sensitiveKeyword].(bool); sensitive && path != "" {
+		*fields = append(*fields, path)
+		return
+	}
+
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		for name, property := range properties {
+			if propertySchema, ok := property.(map[string]interface{}); ok {
+				collectSensitiveFields(propertySchema, path+"/"+escapePointerToken(name), fields)
+			}
+		}
+	}
+
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		collectSensitiveFields(items, path+"/"+anyArrayItem, fields)
+	}
+}
+
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// TransformFields replaces every value found at the given fields of the payload by the transform result.
+// Fields not present in the payload are ignored.
+func TransformFields(
+	payload []byte,
+	fields []string,
+	transform func(value interface{}) (interface{}, error),
+) ([]byte, error) {
+	if len(fields) == 0 {
+		return payload, nil
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(payload, &document); err != nil {
+		return nil, fmt.Errorf("error unmarshaling payload: %w", err)
+	}
+
+	for _, field := range fields {
+		tokens := strings.Split(strings.TrimPrefix(field, "/"), "/")
+		for i := range tokens {
+			tokens[i] = unescapePointerToken(tokens[i])
+		}
+
+		if err := transformField(document, tokens, transform); err != nil {
+			return nil, fmt.Errorf("error transforming field %s: %w", field, err)
+		}
+	}
+
+	return json.Marshal(document)
+}
+
+func transformField(
+	document interface{},
+	tokens []string,
+	transform func(value interface{}) (interface{}, error),
+) error {
+	token, last := tokens[0], len(tokens) == 1
+
+	switch value := document.(type) {
+	case map[string]interface{}:
+		item, ok := value[token]
+		if !ok {
+			return nil
+		}
+		if !last {
+			return transformField(item, tokens[1:], transform)
+		}
+
+		transformed, err := transform(item)
+		if err != nil {
+			return err
+		}
+		value[token] = transformed
+	case []interface{}:
+		if token != anyArrayItem {
+			return nil
+		}
+		for i, item := range value {
+			if !last {
+				if err := transformField(item, tokens[1:], transform); err != nil {
+					return err
+				}
+				continue
+			}
+
+			transformed, err := transform(item)
+			if err != nil {
+				return err
+			}
+			value[i] = transformed
+		}
+	}
+
+	return nil
+}
+
+// RedactFields hides the values of the given fields of the payload.
+func RedactFields(payload []byte, fields []string) ([]byte, error) {
+	return TransformFields(payload, fields, func(_ interface{}) (interface{}, error) {
+		return redactedValue, nil
+	})
+}
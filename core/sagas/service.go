@@ -2,10 +2,12 @@ package sagas
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/qri-io/jsonschema"
@@ -14,13 +16,44 @@ import (
 
 var ErrInvalidJSONSchema = errors.New("invalid json schema")
 var ErrSagaNotFound = errors.New("saga not found")
+var ErrSagaExecutionNotFound = errors.New("saga execution not found")
+var ErrStepExecutionNotFound = errors.New("step execution not found")
+var ErrInvalidStepResult = errors.New("invalid step result")
+var ErrUnexpectedStepResult = errors.New("step is not waiting for this result")
+var ErrSagaDeprecated = errors.New("saga is deprecated")
+var ErrSagaHasExecutions = errors.New("saga has executions")
+var ErrInvalidInputMapping = errors.New("invalid step input mapping")
+var ErrUnsupportedTransport = errors.New("unsupported step transport")
+var ErrInvalidTransportConfig = errors.New("invalid step transport config")
+var ErrInvalidTimeWindow = errors.New("invalid time window, from must be before to")
+var ErrSagaWithoutSteps = errors.New("saga has no steps")
+var ErrEncryptionKeyRequired = errors.New("sensitive fields require an encryption key")
+var ErrEncryptedValue = errors.New("values can't have the format of the encrypted values")
+
+const defaultStatisticsWindow = 24 * time.Hour
+
+// IsPermanentResultError tells whether handling a step result again would fail the same way, the consumers drop
+// those results instead of redelivering them.
+func IsPermanentResultError(err error) bool {
+	return errors.Is(err, ErrInvalidStepResult) ||
+		errors.Is(err, ErrUnexpectedStepResult) ||
+		errors.Is(err, ErrSagaNotFound) ||
+		errors.Is(err, ErrSagaExecutionNotFound) ||
+		errors.Is(err, ErrStepExecutionNotFound)
+}
 
 type Service interface {
 	CreateSaga(ctx context.Context, vo CreateSagaVO) (entities.Saga, error)
 	GetSagaByID(ctx context.Context, sagaID uuid.UUID) (entities.Saga, error)
+	ListSagas(ctx context.Context, vo ListSagasVO) (SagaPageVO, error)
+	UpdateSaga(ctx context.Context, vo UpdateSagaVO) (entities.Saga, error)
+	DeprecateSaga(ctx context.Context, sagaID uuid.UUID) (entities.Saga, error)
+	DeleteSaga(ctx context.Context, sagaID uuid.UUID) error
 	CreateSagaExecution(ctx context.Context, vo CreateSagaExecutionVO) (entities.SagaExecution, error)
 	GetSagaExecution(ctx context.Context, executionID uuid.UUID) (SagaExecutionVO, error)
+	ListSagaExecutions(ctx context.Context, vo ListSagaExecutionsVO) (SagaExecutionPageVO, error)
 	HandleStepResult(ctx context.Context, result StepResultVO) error
+	GetSagaStatistics(ctx context.Context, vo GetSagaStatisticsVO) (SagaStatisticsVO, error)
 }
 
 type service struct {
@@ -42,27 +75,73 @@ func (svc service) CreateSaga(
 	if err := svc.validateJSONSchema(vo.Payload); err != nil {
 		return entities.Saga{}, err
 	}
+	if len(vo.Steps) == 0 {
+		return entities.Saga{}, ErrSagaWithoutSteps
+	}
+	if err := svc.checkCanEncrypt(vo.Payload); err != nil {
+		return entities.Saga{}, err
+	}
+	previousSteps := make(map[string]bool, len(vo.Steps))
+	for _, step := range vo.Steps {
+		if len(step.InputMapping) > 0 && !json.Valid(step.InputMapping) {
+			return entities.Saga{}, ErrInvalidInputMapping
+		}
+		if err := validateInputMapping(step.InputMapping, previousSteps); err != nil {
+			return entities.Saga{}, err
+		}
+		previousSteps[FormatName(step.Name)] = true
+		if len(step.TransportConfig) > 0 && !json.Valid(step.TransportConfig) {
+			return entities.Saga{}, ErrInvalidTransportConfig
+		}
+		if !svc.executionGateway.SupportsTransport(stepTransport(step.Transport)) {
+			return entities.Saga{}, fmt.Errorf("%w: %s", ErrUnsupportedTransport, step.Transport)
+		}
+	}
 
 	saga := entities.Saga{
 		Name:          vo.Name,
-		FormattedName: svc.formatSagaName(vo.Name),
+		FormattedName: FormatName(vo.Name),
+		Description:   vo.Description,
 		Payload:       vo.Payload,
+		RetentionDays: vo.RetentionDays,
 	}
-	savedSaga, err := svc.repository.CreateSaga(ctx, saga)
-	if err != nil {
-		return entities.Saga{}, fmt.Errorf("error saving saga: %w", err)
-	}
-
 	sagaSteps := make([]entities.SagaStep, 0, len(vo.Steps))
 	for index, voStep := range vo.Steps {
 		step := entities.SagaStep{
-			SagaID: savedSaga.SagaID,
-			Index:  index + 1,
+			Index: index + 1,
 			// TODO: Create `formatted_name` attr
-			Name: svc.formatSagaName(voStep.Name),
+			Name:                FormatName(voStep.Name),
+			InputMapping:        voStep.InputMapping,
+			CompensateOnFailure: voStep.CompensateOnFailure,
+			Transport:           stepTransport(voStep.Transport),
+			TransportConfig:     voStep.TransportConfig,
 		}
 		sagaSteps = append(sagaSteps, step)
 	}
+
+	if validator, ok := svc.executionGateway.(StepValidator); ok {
+		for _, step := range sagaSteps {
+			if err := validator.ValidateStep(step); err != nil {
+				return entities.Saga{}, fmt.Errorf("%w: step %s: %v", ErrInvalidTransportConfig, step.Name, err)
+			}
+		}
+	}
+
+	// Steps are provisioned before the saga is saved, so a saga is never left without its resources
+	if provisioner, ok := svc.executionGateway.(StepProvisioner); ok {
+		if err := provisioner.ProvisionSteps(ctx, saga.FormattedName, sagaSteps); err != nil {
+			return entities.Saga{}, fmt.Errorf("error provisioning saga steps: %w", err)
+		}
+	}
+
+	savedSaga, err := svc.repository.CreateSaga(ctx, saga)
+	if err != nil {
+		return entities.Saga{}, fmt.Errorf("error saving saga: %w", err)
+	}
+
+	for index := range sagaSteps {
+		sagaSteps[index].SagaID = savedSaga.SagaID
+	}
 	_, err = svc.repository.CreateSagaSteps(ctx, sagaSteps)
 	if err != nil {
 		return entities.Saga{}, fmt.Errorf("error saving saga steps: %w", err)
@@ -71,7 +150,15 @@ func (svc service) CreateSaga(
 	return savedSaga, nil
 }
 
-func (svc service) formatSagaName(name string) string {
+func stepTransport(transport entities.StepTransport) entities.StepTransport {
+	if transport == "" {
+		return entities.StepTransportKafka
+	}
+	return transport
+}
+
+// FormatName is how saga and step names are kept, they're part of the topics and queues names.
+func FormatName(name string) string {
 	return strings.ToLower(
 		strings.ReplaceAll(
 			strings.ReplaceAll(name, " ", "-"), "_", "-",
@@ -79,6 +166,43 @@ func (svc service) formatSagaName(name string) string {
 	)
 }
 
+// checkCanEncrypt refuses sagas with sensitive fields when there's no key to encrypt them.
+func (svc service) checkCanEncrypt(schema []byte) error {
+	encrypter, ok := svc.repository.(PayloadEncrypter)
+	if !ok {
+		return nil
+	}
+
+	fields, err := SensitiveFields(schema)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 && !encrypter.CanEncrypt() {
+		return ErrEncryptionKeyRequired
+	}
+
+	return nil
+}
+
+// checkEncryptedValues refuses the documents sent by clients with values looking encrypted, they'd be taken for
+// encrypted ones when read.
+func (svc service) checkEncryptedValues(document []byte) error {
+	encrypter, ok := svc.repository.(PayloadEncrypter)
+	if !ok {
+		return nil
+	}
+
+	found, err := encrypter.HasEncryptedValues(document)
+	if err != nil {
+		return err
+	}
+	if found {
+		return ErrEncryptedValue
+	}
+
+	return nil
+}
+
 func (svc service) validateJSONSchema(payload []byte) error {
 	jsonSchema := jsonschema.Schema{}
 	if err := jsonSchema.UnmarshalJSON(payload); err != nil {
@@ -95,6 +219,87 @@ func (svc service) GetSagaByID(
 	return svc.repository.GetSaga(ctx, sagaID)
 }
 
+func (svc service) ListSagas(ctx context.Context, vo ListSagasVO) (SagaPageVO, error) {
+	cursor, err := DecodeCursor(vo.Cursor)
+	if err != nil {
+		return SagaPageVO{}, err
+	}
+
+	limit := pageSize(vo.Limit)
+	filter := SagaFilter{
+		Name:          vo.Name,
+		FormattedName: vo.FormattedName,
+		CreatedAfter:  vo.CreatedAfter,
+		CreatedBefore: vo.CreatedBefore,
+		Deprecated:    vo.Deprecated,
+		After:         cursor,
+		Limit:         limit + 1,
+	}
+	foundSagas, err := svc.repository.ListSagas(ctx, filter)
+	if err != nil {
+		return SagaPageVO{}, err
+	}
+
+	page := SagaPageVO{Sagas: make([]SagaVO, 0, limit)}
+	if len(foundSagas) > limit {
+		foundSagas = foundSagas[:limit]
+		lastSaga := foundSagas[limit-1]
+		page.NextCursor = EncodeCursor(Cursor{CreatedAt: lastSaga.CreatedAt, ID: lastSaga.SagaID})
+	}
+
+	sagaIDs := make([]uuid.UUID, 0, len(foundSagas))
+	for _, saga := range foundSagas {
+		sagaIDs = append(sagaIDs, saga.SagaID)
+	}
+	steps, err := svc.repository.GetSagaStepsBySagaIDs(ctx, sagaIDs)
+	if err != nil {
+		return SagaPageVO{}, err
+	}
+
+	stepsBySaga := make(map[uuid.UUID][]entities.SagaStep, len(foundSagas))
+	for _, step := range steps {
+		stepsBySaga[step.SagaID] = append(stepsBySaga[step.SagaID], step)
+	}
+	for _, saga := range foundSagas {
+		page.Sagas = append(page.Sagas, SagaVO{Saga: saga, Steps: stepsBySaga[saga.SagaID]})
+	}
+
+	return page, nil
+}
+
+// UpdateSaga changes the saga description attributes, the formatted name is kept since the steps topics use it.
+func (svc service) UpdateSaga(ctx context.Context, vo UpdateSagaVO) (entities.Saga, error) {
+	saga, err := svc.repository.GetSaga(ctx, vo.SagaID)
+	if err != nil {
+		return entities.Saga{}, err
+	}
+
+	if vo.Name != nil {
+		saga.Name = *vo.Name
+	}
+	if vo.Description != nil {
+		saga.Description = *vo.Description
+	}
+	if vo.RetentionDays != nil {
+		saga.RetentionDays = *vo.RetentionDays
+	}
+
+	return svc.repository.UpdateSaga(ctx, saga)
+}
+
+// DeprecateSaga rejects new executions of the saga, the running ones are still handled.
+func (svc service) DeprecateSaga(ctx context.Context, sagaID uuid.UUID) (entities.Saga, error) {
+	return svc.repository.DeprecateSaga(ctx, sagaID)
+}
+
+func (svc service) DeleteSaga(ctx context.Context, sagaID uuid.UUID) error {
+	if _, err := svc.repository.GetSaga(ctx, sagaID); err != nil {
+		return err
+	}
+
+	return svc.repository.DeleteSaga(ctx, sagaID)
+}
+
 func (svc service) CreateSagaExecution(
 	ctx context.Context,
 	vo CreateSagaExecutionVO,
@@ -103,19 +308,29 @@ func (svc service) CreateSagaExecution(
 	if err != nil {
 		return entities.SagaExecution{}, err
 	}
+	if saga.IsDeprecated() {
+		return entities.SagaExecution{}, ErrSagaDeprecated
+	}
 	sagaSteps, err := svc.repository.GetSagaStepsBySagaID(ctx, saga.SagaID)
 	if err != nil {
 		return entities.SagaExecution{}, err
 	}
+	if len(sagaSteps) == 0 {
+		return entities.SagaExecution{}, ErrSagaWithoutSteps
+	}
 
 	err = svc.validatePayload(ctx, saga.Payload, vo.Payload)
 	if err != nil {
 		return entities.SagaExecution{}, err
 	}
+	if err := svc.checkEncryptedValues(vo.Payload); err != nil {
+		return entities.SagaExecution{}, fmt.Errorf("payload error: %w", err)
+	}
 
 	sagaExecution := entities.SagaExecution{
 		SagaID:  saga.SagaID,
 		Payload: vo.Payload,
+		Status:  entities.SagaExecutionRunning,
 	}
 	savedExecution, err := svc.repository.CreateSagaExecution(ctx, sagaExecution)
 	if err != nil {
@@ -125,10 +340,14 @@ func (svc service) CreateSagaExecution(
 	sagaExecutionSteps := make([]entities.StepExecution, 0, len(sagaSteps))
 	for _, step := range sagaSteps {
 		stepExecution := entities.StepExecution{
-			SagaExecutionID: savedExecution.SagaExecutionID,
-			Index:           step.Index,
-			Name:            step.Name,
-			Status:          entities.StepExecutionRegistered,
+			SagaExecutionID:     savedExecution.SagaExecutionID,
+			Index:               step.Index,
+			Name:                step.Name,
+			Status:              entities.StepExecutionRegistered,
+			InputMapping:        step.InputMapping,
+			CompensateOnFailure: step.CompensateOnFailure,
+			Transport:           step.Transport,
+			TransportConfig:     step.TransportConfig,
 		}
 		sagaExecutionSteps = append(sagaExecutionSteps, stepExecution)
 	}
@@ -145,7 +364,7 @@ func (svc service) CreateSagaExecution(
 		return entities.SagaExecution{}, err
 	}
 
-	err = svc.executionGateway.SendStepToExecute(saga.FormattedName, savedExecution, sagaExecutionSteps[0], false)
+	err = svc.sendStepAttempt(ctx, saga.FormattedName, savedExecution, firstStep, sagaExecutionSteps, false)
 	if err != nil {
 		return entities.SagaExecution{}, fmt.Errorf("error sending the first step to be executed: %w", err)
 	}
@@ -181,13 +400,133 @@ func (svc service) GetSagaExecution(ctx context.Context, executionID uuid.UUID)
 		return SagaExecutionVO{}, err
 	}
 
+	saga, err := svc.repository.GetSaga(ctx, sagaExecution.SagaID)
+	if err != nil {
+		return SagaExecutionVO{}, err
+	}
+	sensitiveFields, err := SensitiveFields(saga.Payload)
+	if err != nil {
+		return SagaExecutionVO{}, err
+	}
+
 	vo := SagaExecutionVO{
-		SagaExecution: sagaExecution,
-		Steps:         stepsExecution,
+		SagaExecution:   sagaExecution,
+		Steps:           stepsExecution,
+		SensitiveFields: sensitiveFields,
 	}
 	return vo, nil
 }
 
+// ListSagaExecutions returns the executions with their steps, payloads are only returned by GetSagaExecution.
+func (svc service) ListSagaExecutions(ctx context.Context, vo ListSagaExecutionsVO) (SagaExecutionPageVO, error) {
+	cursor, err := DecodeCursor(vo.Cursor)
+	if err != nil {
+		return SagaExecutionPageVO{}, err
+	}
+
+	if vo.SagaID != nil {
+		if _, err := svc.repository.GetSaga(ctx, *vo.SagaID); err != nil {
+			return SagaExecutionPageVO{}, err
+		}
+	}
+
+	limit := pageSize(vo.Limit)
+	filter := SagaExecutionFilter{
+		SagaID:        vo.SagaID,
+		Status:        vo.Status,
+		StepStatus:    vo.StepStatus,
+		CreatedAfter:  vo.CreatedAfter,
+		CreatedBefore: vo.CreatedBefore,
+		After:         cursor,
+		Limit:         limit + 1,
+	}
+	executions, err := svc.repository.ListSagaExecutions(ctx, filter)
+	if err != nil {
+		return SagaExecutionPageVO{}, err
+	}
+
+	page := SagaExecutionPageVO{Executions: make([]SagaExecutionVO, 0, limit)}
+	if len(executions) > limit {
+		executions = executions[:limit]
+		lastExecution := executions[limit-1]
+		page.NextCursor = EncodeCursor(Cursor{CreatedAt: lastExecution.CreatedAt, ID: lastExecution.SagaExecutionID})
+	}
+
+	executionIDs := make([]uuid.UUID, 0, len(executions))
+	for _, execution := range executions {
+		executionIDs = append(executionIDs, execution.SagaExecutionID)
+	}
+	steps, err := svc.repository.GetSagaStepsExecutionByExecutionIDs(ctx, executionIDs)
+	if err != nil {
+		return SagaExecutionPageVO{}, err
+	}
+
+	stepsByExecution := make(map[uuid.UUID][]entities.StepExecution, len(executions))
+	for _, step := range steps {
+		stepsByExecution[step.SagaExecutionID] = append(stepsByExecution[step.SagaExecutionID], step)
+	}
+	for _, execution := range executions {
+		page.Executions = append(page.Executions, SagaExecutionVO{
+			SagaExecution: execution,
+			Steps:         stepsByExecution[execution.SagaExecutionID],
+		})
+	}
+
+	return page, nil
+}
+
+// GetSagaStatistics aggregates the executions created between from and to, the last 24 hours by default.
+func (svc service) GetSagaStatistics(ctx context.Context, vo GetSagaStatisticsVO) (SagaStatisticsVO, error) {
+	to := time.Now().UTC()
+	if vo.To != nil {
+		to = *vo.To
+	}
+	from := to.Add(-defaultStatisticsWindow)
+	if vo.From != nil {
+		from = *vo.From
+	}
+	if !from.Before(to) {
+		return SagaStatisticsVO{}, ErrInvalidTimeWindow
+	}
+
+	if _, err := svc.repository.GetSaga(ctx, vo.SagaID); err != nil {
+		return SagaStatisticsVO{}, err
+	}
+
+	executions, err := svc.repository.GetSagaExecutionStatistics(ctx, vo.SagaID, from, to)
+	if err != nil {
+		return SagaStatisticsVO{}, err
+	}
+	steps, err := svc.repository.GetSagaStepStatistics(ctx, vo.SagaID, from, to)
+	if err != nil {
+		return SagaStatisticsVO{}, err
+	}
+
+	statistics := SagaStatisticsVO{
+		SagaID:           vo.SagaID,
+		From:             from,
+		To:               to,
+		Executions:       executions,
+		CompensationRate: rate(executions.Compensated, executions.Total),
+		Steps:            make([]StepStatisticsVO, 0, len(steps)),
+	}
+	for _, step := range steps {
+		statistics.Steps = append(statistics.Steps, StepStatisticsVO{
+			StepStatistics: step,
+			FailureRate:    rate(step.Failed, step.Total),
+		})
+	}
+
+	return statistics, nil
+}
+
+func rate(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
+
 func (svc service) HandleStepResult(ctx context.Context, result StepResultVO) error {
 	switch result.Result {
 	case "success":
@@ -196,12 +535,18 @@ func (svc service) HandleStepResult(ctx context.Context, result StepResultVO) er
 		return svc.onFailureResult(ctx, result)
 	case "compensated":
 		return svc.onCompensation(ctx, result)
+	case "compensation_failed":
+		return svc.onCompensationFailure(ctx, result)
 	default:
-		return nil
+		return fmt.Errorf("%w: %s", ErrInvalidStepResult, result.Result)
 	}
 }
 
 func (svc service) onSuccessResult(ctx context.Context, result StepResultVO) error {
+	if err := svc.checkEncryptedValues(result.Output); err != nil {
+		return fmt.Errorf("%w: output: %v", ErrInvalidStepResult, err)
+	}
+
 	// Get Saga Execution
 	sagaExecution, err := svc.repository.GetSagaExecution(ctx, result.ExecutionID)
 	if err != nil {
@@ -213,6 +558,13 @@ func (svc service) onSuccessResult(ctx context.Context, result StepResultVO) err
 	if err != nil {
 		return err
 	}
+	if err := checkAwaitsResult(findCurrentStep(result.StepIndex, stepsExecution), result.Result); err != nil {
+		return err
+	}
+	if hasMovedOn(findNextStep(result.StepIndex, stepsExecution), false) {
+		log.Printf("result of step %d of execution %s was already handled", result.StepIndex, result.ExecutionID)
+		return nil
+	}
 
 	// Mark the received step result as finished
 	err = svc.repository.SetSagaStepExecutionStatus(
@@ -222,12 +574,20 @@ func (svc service) onSuccessResult(ctx context.Context, result StepResultVO) err
 		return err
 	}
 
+	// Keep the step output so the next steps can map it into their payload
+	if len(result.Output) > 0 {
+		err = svc.repository.SetSagaStepExecutionOutput(ctx, result.Output, result.StepIndex, result.ExecutionID)
+		if err != nil {
+			return err
+		}
+		setStepOutput(result.StepIndex, result.Output, stepsExecution)
+	}
+
 	// Get the next step and mark it as started
 	nextStep := findNextStep(result.StepIndex, stepsExecution)
 	if nextStep == nil {
-		// Make something better later
 		log.Println("Saga was finished")
-		return nil
+		return svc.repository.SetSagaExecutionStatus(ctx, entities.SagaExecutionSucceeded, result.ExecutionID)
 	}
 
 	err = svc.repository.SetSagaStepExecutionStatus(
@@ -238,12 +598,7 @@ func (svc service) onSuccessResult(ctx context.Context, result StepResultVO) err
 	}
 
 	// Send the next step
-	err = svc.executionGateway.SendStepToExecute(result.SagaName, sagaExecution, *nextStep, false)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return svc.sendStep(ctx, sagaExecution, *nextStep, stepsExecution, false)
 }
 
 func (svc service) onFailureResult(ctx context.Context, result StepResultVO) error {
@@ -258,6 +613,20 @@ func (svc service) onFailureResult(ctx context.Context, result StepResultVO) err
 	if err != nil {
 		return err
 	}
+	failedStep := findCurrentStep(result.StepIndex, stepsExecution)
+	if err := checkAwaitsResult(failedStep, result.Result); err != nil {
+		return err
+	}
+
+	// Get the next step, steps that may partially succeed are compensated themselves first
+	nextStep := findPreviousStep(result.StepIndex, stepsExecution)
+	if failedStep.CompensateOnFailure {
+		nextStep = failedStep
+	}
+	if hasMovedOn(nextStep, true) {
+		log.Printf("result of step %d of execution %s was already handled", result.StepIndex, result.ExecutionID)
+		return nil
+	}
 
 	// Mark the received step result as finished
 	err = svc.repository.SetSagaStepExecutionStatus(
@@ -267,12 +636,22 @@ func (svc service) onFailureResult(ctx context.Context, result StepResultVO) err
 		return err
 	}
 
-	// Get the next step and mark it as started
-	nextStep := findPreviousStep(result.StepIndex, stepsExecution)
+	// Keep why the step failed
+	if result.Error != nil {
+		err = svc.repository.SetSagaStepExecutionError(ctx, *result.Error, result.StepIndex, result.ExecutionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Mark the next step as started
 	if nextStep == nil {
-		// Make something better later
 		log.Println("Saga has nothing to compensate")
-		return nil
+		return svc.repository.SetSagaExecutionStatus(ctx, entities.SagaExecutionFailed, result.ExecutionID)
+	}
+	err = svc.repository.SetSagaExecutionStatus(ctx, entities.SagaExecutionCompensating, result.ExecutionID)
+	if err != nil {
+		return err
 	}
 	err = svc.repository.SetSagaStepExecutionStatus(
 		ctx, entities.StepExecutionInCompensation, nextStep.Index, result.ExecutionID,
@@ -282,12 +661,7 @@ func (svc service) onFailureResult(ctx context.Context, result StepResultVO) err
 	}
 
 	// Send the next step
-	err = svc.executionGateway.SendStepToExecute(result.SagaName, sagaExecution, *nextStep, true)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return svc.sendStep(ctx, sagaExecution, *nextStep, stepsExecution, true)
 }
 
 func (svc service) onCompensation(ctx context.Context, result StepResultVO) error {
@@ -302,6 +676,13 @@ func (svc service) onCompensation(ctx context.Context, result StepResultVO) erro
 	if err != nil {
 		return err
 	}
+	if err := checkAwaitsResult(findCurrentStep(result.StepIndex, stepsExecution), result.Result); err != nil {
+		return err
+	}
+	if hasMovedOn(findPreviousStep(result.StepIndex, stepsExecution), true) {
+		log.Printf("result of step %d of execution %s was already handled", result.StepIndex, result.ExecutionID)
+		return nil
+	}
 
 	// Mark the received step result as finished
 	err = svc.repository.SetSagaStepExecutionStatus(
@@ -314,9 +695,8 @@ func (svc service) onCompensation(ctx context.Context, result StepResultVO) erro
 	// Get the next step and mark it as started
 	nextStep := findPreviousStep(result.StepIndex, stepsExecution)
 	if nextStep == nil {
-		// Make something better later
 		log.Println("Saga finished compensation")
-		return nil
+		return svc.repository.SetSagaExecutionStatus(ctx, entities.SagaExecutionFailed, result.ExecutionID)
 	}
 	err = svc.repository.SetSagaStepExecutionStatus(
 		ctx, entities.StepExecutionInCompensation, nextStep.Index, result.ExecutionID,
@@ -326,14 +706,111 @@ func (svc service) onCompensation(ctx context.Context, result StepResultVO) erro
 	}
 
 	// Send the next step
-	err = svc.executionGateway.SendStepToExecute(result.SagaName, sagaExecution, *nextStep, true)
+	return svc.sendStep(ctx, sagaExecution, *nextStep, stepsExecution, true)
+}
+
+// onCompensationFailure fails the execution when a step can't be compensated, e.g. its transport gave up retrying,
+// leaving the step at the error status with why it couldn't be compensated.
+func (svc service) onCompensationFailure(ctx context.Context, result StepResultVO) error {
+	stepsExecution, err := svc.repository.GetSagaStepsExecutionByExecutionID(ctx, result.ExecutionID)
 	if err != nil {
 		return err
 	}
+	if err := checkAwaitsResult(findCurrentStep(result.StepIndex, stepsExecution), result.Result); err != nil {
+		return err
+	}
+
+	err = svc.repository.SetSagaStepExecutionStatus(
+		ctx, entities.StepExecutionError, result.StepIndex, result.ExecutionID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.Error != nil {
+		err = svc.repository.SetSagaStepExecutionError(ctx, *result.Error, result.StepIndex, result.ExecutionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Printf("step %d of execution %s couldn't be compensated", result.StepIndex, result.ExecutionID)
+	return svc.repository.SetSagaExecutionStatus(ctx, entities.SagaExecutionFailed, result.ExecutionID)
+}
+
+// sendStep sends the step to the saga of the execution, never to the one a result claims to be from.
+func (svc service) sendStep(
+	ctx context.Context,
+	sagaExecution entities.SagaExecution,
+	step entities.StepExecution,
+	stepsExecution []entities.StepExecution,
+	isCompensation bool,
+) error {
+	saga, err := svc.repository.GetSaga(ctx, sagaExecution.SagaID)
+	if err != nil {
+		return err
+	}
+
+	return svc.sendStepAttempt(ctx, saga.FormattedName, sagaExecution, step, stepsExecution, isCompensation)
+}
+
+// sendStepAttempt counts a new attempt of the step before sending it, so every send has its own attempt, the ones of
+// results handled again included.
+func (svc service) sendStepAttempt(
+	ctx context.Context,
+	sagaName string,
+	sagaExecution entities.SagaExecution,
+	step entities.StepExecution,
+	stepsExecution []entities.StepExecution,
+	isCompensation bool,
+) error {
+	attempt, err := svc.repository.IncrementSagaStepExecutionAttempt(ctx, step.Index, sagaExecution.SagaExecutionID)
+	if err != nil {
+		return fmt.Errorf("error counting the step attempt: %w", err)
+	}
+	step.Attempt = attempt
+
+	return svc.executionGateway.SendStepToExecute(sagaName, sagaExecution, step, stepsExecution, isCompensation)
+}
+
+// checkAwaitsResult refuses the results of steps that aren't waiting for them, e.g. the success of an unstarted step.
+// Results of steps already at the status the result gives them are redeliveries and handled as such.
+func checkAwaitsResult(step *entities.StepExecution, result string) error {
+	if step == nil {
+		return ErrStepExecutionNotFound
+	}
+
+	awaits := false
+	switch result {
+	case "success":
+		awaits = step.Status == entities.StepExecutionStarted || step.Status == entities.StepExecutionFinished
+	case "error":
+		awaits = step.Status == entities.StepExecutionStarted || step.Failed
+	case "compensated":
+		awaits = step.Status == entities.StepExecutionInCompensation || step.Status == entities.StepExecutionCompensated
+	case "compensation_failed":
+		awaits = step.Status == entities.StepExecutionInCompensation
+	}
+	if !awaits {
+		return fmt.Errorf("%w: step %d is %s", ErrUnexpectedStepResult, step.Index, step.Status)
+	}
 
 	return nil
 }
 
+// hasMovedOn tells whether the step a result sends already went past the status the result gives it, meaning the
+// result was handled before, e.g. redelivered after its offset failed to be committed, and the saga moved on. Results
+// handled again before that send the step again, as the first handling may have failed before sending it.
+func hasMovedOn(step *entities.StepExecution, isCompensation bool) bool {
+	if step == nil {
+		return false
+	}
+	if isCompensation {
+		return step.Status == entities.StepExecutionCompensated
+	}
+
+	return step.Status != entities.StepExecutionRegistered && step.Status != entities.StepExecutionStarted
+}
+
 func findNextStep(currentIndexStep int, steps []entities.StepExecution) *entities.StepExecution {
 	if currentIndexStep < len(steps) {
 		return &steps[currentIndexStep]
@@ -342,6 +819,15 @@ func findNextStep(currentIndexStep int, steps []entities.StepExecution) *entitie
 	return nil
 }
 
+func findCurrentStep(currentIndexStep int, steps []entities.StepExecution) *entities.StepExecution {
+	stepIndex := currentIndexStep - 1
+	if stepIndex >= 0 && stepIndex < len(steps) {
+		return &steps[stepIndex]
+	}
+
+	return nil
+}
+
 func findPreviousStep(currentIndexStep int, steps []entities.StepExecution) *entities.StepExecution {
 	stepIndex := currentIndexStep - 2
 	if stepIndex >= 0 {
@@ -350,3 +836,11 @@ func findPreviousStep(currentIndexStep int, steps []entities.StepExecution) *ent
 
 	return nil
 }
+
+func setStepOutput(stepIndex int, output []byte, steps []entities.StepExecution) {
+	for i := range steps {
+		if steps[i].Index == stepIndex {
+			steps[i].Output = output
+		}
+	}
+}
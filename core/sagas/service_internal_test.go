@@ -0,0 +1,110 @@
+package sagas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/thepabloaguilar/sukuna/core/entities"
+)
+
+func TestCheckAwaitsResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		status entities.StepExecutionStatus
+		failed bool
+		result string
+		awaits bool
+	}{
+		{name: "success of a started step", status: entities.StepExecutionStarted, result: "success", awaits: true},
+		{name: "success of a finished step", status: entities.StepExecutionFinished, result: "success", awaits: true},
+		{name: "success of a registered step", status: entities.StepExecutionRegistered, result: "success"},
+		{name: "success of a failed step", status: entities.StepExecutionError, failed: true, result: "success"},
+		{name: "error of a started step", status: entities.StepExecutionStarted, result: "error", awaits: true},
+		{name: "error of a failed step", status: entities.StepExecutionError, failed: true, result: "error", awaits: true},
+		{
+			name:   "error of a failed step compensating itself",
+			status: entities.StepExecutionInCompensation,
+			failed: true,
+			result: "error",
+			awaits: true,
+		},
+		{name: "error of a finished step", status: entities.StepExecutionFinished, result: "error"},
+		{
+			name:   "compensation of a step in compensation",
+			status: entities.StepExecutionInCompensation,
+			result: "compensated",
+			awaits: true,
+		},
+		{
+			name:   "compensation of a compensated step",
+			status: entities.StepExecutionCompensated,
+			result: "compensated",
+			awaits: true,
+		},
+		{name: "compensation of a finished step", status: entities.StepExecutionFinished, result: "compensated"},
+		{
+			name:   "compensation failure of a step in compensation",
+			status: entities.StepExecutionInCompensation,
+			result: "compensation_failed",
+			awaits: true,
+		},
+		{
+			name:   "compensation failure of a compensated step",
+			status: entities.StepExecutionCompensated,
+			result: "compensation_failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			step := entities.StepExecution{Status: test.status, Failed: test.failed}
+
+			err := checkAwaitsResult(&step, test.result)
+
+			if test.awaits && err != nil {
+				t.Fatalf("expected the step to await the result, got %v", err)
+			}
+			if !test.awaits && !errors.Is(err, ErrUnexpectedStepResult) {
+				t.Fatalf("expected ErrUnexpectedStepResult, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckAwaitsResultOfUnknownSteps(t *testing.T) {
+	if err := checkAwaitsResult(nil, "success"); !errors.Is(err, ErrStepExecutionNotFound) {
+		t.Fatalf("expected ErrStepExecutionNotFound, got %v", err)
+	}
+}
+
+func TestHasMovedOn(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         entities.StepExecutionStatus
+		isCompensation bool
+		movedOn        bool
+	}{
+		{name: "registered next step", status: entities.StepExecutionRegistered},
+		{name: "started next step", status: entities.StepExecutionStarted},
+		{name: "finished next step", status: entities.StepExecutionFinished, movedOn: true},
+		{name: "failed next step", status: entities.StepExecutionError, movedOn: true},
+		{name: "step in compensation", status: entities.StepExecutionInCompensation, isCompensation: true},
+		{name: "compensated step", status: entities.StepExecutionCompensated, isCompensation: true, movedOn: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			step := entities.StepExecution{Status: test.status}
+
+			if movedOn := hasMovedOn(&step, test.isCompensation); movedOn != test.movedOn {
+				t.Fatalf("expected moved on to be %v, got %v", test.movedOn, movedOn)
+			}
+		})
+	}
+}
+
+func TestHasMovedOnWithoutStep(t *testing.T) {
+	if hasMovedOn(nil, false) || hasMovedOn(nil, true) {
+		t.Fatal("expected the last and first steps to never have moved on")
+	}
+}
@@ -0,0 +1,455 @@
+package sagas_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/gateways/memory"
+)
+
+// sentStep is a step sent through the gateway
+type sentStep struct {
+	sagaName       string
+	index          int
+	isCompensation bool
+}
+
+// recordingGateway supports the kafka transport and records the steps sent, instead of sending them
+type recordingGateway struct {
+	sent *[]sentStep
+}
+
+func (g recordingGateway) SupportsTransport(transport entities.StepTransport) bool {
+	return transport == entities.StepTransportKafka
+}
+
+func (g recordingGateway) SendStepToExecute(
+	sagaName string,
+	_ entities.SagaExecution,
+	sagaStep entities.StepExecution,
+	_ []entities.StepExecution,
+	isCompensation bool,
+) error {
+	*g.sent = append(*g.sent, sentStep{sagaName: sagaName, index: sagaStep.Index, isCompensation: isCompensation})
+	return nil
+}
+
+type testService struct {
+	sagas.Service
+
+	repository memory.SagaRepository
+	sent       *[]sentStep
+}
+
+func newTestService() testService {
+	repository := memory.NewSagaRepository()
+	sent := &[]sentStep{}
+
+	return testService{
+		Service:    sagas.NewService(repository, recordingGateway{sent: sent}),
+		repository: repository,
+		sent:       sent,
+	}
+}
+
+var tripSaga = sagas.CreateSagaVO{
+	Name:    "Trip Booking",
+	Payload: []byte(`{"type": "object"}`),
+	Steps: []sagas.CreateSagaVOSteps{
+		{Name: "Hotel"},
+		{Name: "Flight", InputMapping: []byte(`{"hotel": "{{ /steps/hotel/booking_id }}"}`)},
+		{Name: "Payment"},
+	},
+}
+
+// execute creates the trip saga and executes it, the hotel step is sent
+func (s testService) execute(t *testing.T) entities.SagaExecution {
+	ctx := context.Background()
+	saga, err := s.CreateSaga(ctx, tripSaga)
+	if err != nil {
+		t.Fatalf("error creating the saga: %v", err)
+	}
+	execution, err := s.CreateSagaExecution(ctx, sagas.CreateSagaExecutionVO{
+		SagaID:  saga.SagaID,
+		Payload: []byte(`{"customer": "pablo"}`),
+	})
+	if err != nil {
+		t.Fatalf("error creating the execution: %v", err)
+	}
+
+	return execution
+}
+
+func (s testService) handle(t *testing.T, execution entities.SagaExecution, index int, result string) {
+	err := s.HandleStepResult(context.Background(), sagas.StepResultVO{
+		StepIndex:   index,
+		ExecutionID: execution.SagaExecutionID,
+		Result:      result,
+		Output:      json.RawMessage(`{"booking_id": "42"}`),
+	})
+	if err != nil {
+		t.Fatalf("error handling the %s of step %d: %v", result, index, err)
+	}
+}
+
+func (s testService) steps(t *testing.T, execution entities.SagaExecution) []entities.StepExecution {
+	steps, err := s.repository.GetSagaStepsExecutionByExecutionID(context.Background(), execution.SagaExecutionID)
+	if err != nil {
+		t.Fatalf("error getting the steps: %v", err)
+	}
+
+	return steps
+}
+
+func (s testService) expectSent(t *testing.T, expected ...sentStep) {
+	if len(*s.sent) != len(expected) {
+		t.Fatalf("expected the steps %v to be sent, got %v", expected, *s.sent)
+	}
+	for i := range expected {
+		if (*s.sent)[i] != expected[i] {
+			t.Fatalf("expected the steps %v to be sent, got %v", expected, *s.sent)
+		}
+	}
+}
+
+func TestCreateSagaRejectsSagasWithoutSteps(t *testing.T) {
+	service := newTestService()
+
+	_, err := service.CreateSaga(context.Background(), sagas.CreateSagaVO{
+		Name:    "Empty Trip",
+		Payload: []byte(`{}`),
+	})
+
+	if !errors.Is(err, sagas.ErrSagaWithoutSteps) {
+		t.Fatalf("expected ErrSagaWithoutSteps, got %v", err)
+	}
+}
+
+func TestCreateSagaRejectsInvalidSteps(t *testing.T) {
+	tests := []struct {
+		name     string
+		step     sagas.CreateSagaVOSteps
+		expected error
+	}{
+		{
+			name:     "invalid input mapping",
+			step:     sagas.CreateSagaVOSteps{Name: "Hotel", InputMapping: []byte(`{"customer":`)},
+			expected: sagas.ErrInvalidInputMapping,
+		},
+		{
+			name:     "reference to a later step",
+			step:     sagas.CreateSagaVOSteps{Name: "Hotel", InputMapping: []byte(`{"seat": "{{ /steps/hotel/seat }}"}`)},
+			expected: sagas.ErrInvalidInputMapping,
+		},
+		{
+			name:     "invalid transport config",
+			step:     sagas.CreateSagaVOSteps{Name: "Hotel", TransportConfig: []byte(`{"topic":`)},
+			expected: sagas.ErrInvalidTransportConfig,
+		},
+		{
+			name:     "unsupported transport",
+			step:     sagas.CreateSagaVOSteps{Name: "Hotel", Transport: entities.StepTransportHTTP},
+			expected: sagas.ErrUnsupportedTransport,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := newTestService()
+
+			_, err := service.CreateSaga(context.Background(), sagas.CreateSagaVO{
+				Name:    "Trip Booking",
+				Payload: []byte(`{}`),
+				Steps:   []sagas.CreateSagaVOSteps{test.step},
+			})
+
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, err)
+			}
+			if found, _ := service.repository.ListSagas(context.Background(), sagas.SagaFilter{Limit: 10}); len(found) != 0 {
+				t.Fatalf("expected the saga not to be created, got %v", found)
+			}
+		})
+	}
+}
+
+func TestCreateSagaExecutionRejectsSagasWithoutSteps(t *testing.T) {
+	service := newTestService()
+	// Sagas created before the steps were required
+	saga, err := service.repository.CreateSaga(context.Background(), entities.Saga{
+		Name: "Empty Trip", FormattedName: "empty-trip", Payload: []byte(`{}`),
+	})
+	if err != nil {
+		t.Fatalf("error creating the saga: %v", err)
+	}
+
+	_, err = service.CreateSagaExecution(context.Background(), sagas.CreateSagaExecutionVO{
+		SagaID:  saga.SagaID,
+		Payload: []byte(`{}`),
+	})
+
+	if !errors.Is(err, sagas.ErrSagaWithoutSteps) {
+		t.Fatalf("expected ErrSagaWithoutSteps, got %v", err)
+	}
+	service.expectSent(t)
+}
+
+func TestSuccessfulStepsSendTheNextStep(t *testing.T) {
+	service := newTestService()
+	execution := service.execute(t)
+
+	service.handle(t, execution, 1, "success")
+
+	steps := service.steps(t, execution)
+	if steps[0].Status != entities.StepExecutionFinished || steps[1].Status != entities.StepExecutionStarted {
+		t.Fatalf("expected the hotel finished and the flight started, got %s and %s", steps[0].Status, steps[1].Status)
+	}
+	if string(steps[0].Output) != `{"booking_id": "42"}` {
+		t.Fatalf("expected the hotel output to be kept, got %s", steps[0].Output)
+	}
+	service.expectSent(t, sentStep{sagaName: "trip-booking", index: 1}, sentStep{sagaName: "trip-booking", index: 2})
+}
+
+func TestRedeliveredResultsSendTheNextStepAgain(t *testing.T) {
+	service := newTestService()
+	execution := service.execute(t)
+
+	// The first handling may have failed before sending the flight step
+	service.handle(t, execution, 1, "success")
+	service.handle(t, execution, 1, "success")
+
+	service.expectSent(t,
+		sentStep{sagaName: "trip-booking", index: 1},
+		sentStep{sagaName: "trip-booking", index: 2},
+		sentStep{sagaName: "trip-booking", index: 2},
+	)
+}
+
+func TestRedeliveredResultsAreIgnoredOnceTheSagaMovedOn(t *testing.T) {
+	service := newTestService()
+	execution := service.execute(t)
+	service.handle(t, execution, 1, "success")
+	service.handle(t, execution, 2, "success")
+
+	service.handle(t, execution, 1, "success")
+
+	service.expectSent(t,
+		sentStep{sagaName: "trip-booking", index: 1},
+		sentStep{sagaName: "trip-booking", index: 2},
+		sentStep{sagaName: "trip-booking", index: 3},
+	)
+}
+
+func TestEverySendCountsAnAttemptOfTheStep(t *testing.T) {
+	service := newTestService()
+	execution := service.execute(t)
+
+	service.handle(t, execution, 1, "success")
+	service.handle(t, execution, 1, "success")
+	service.handle(t, execution, 2, "error")
+
+	steps := service.steps(t, execution)
+	// The hotel is sent and compensated, the flight is sent again by the redelivered hotel result
+	for i, expected := range []int{2, 2, 0} {
+		if steps[i].Attempt != expected {
+			t.Fatalf("expected step %d at attempt %d, got %d", steps[i].Index, expected, steps[i].Attempt)
+		}
+	}
+}
+
+func TestFailedStepsCompensateThePreviousSteps(t *testing.T) {
+	service := newTestService()
+	execution := service.execute(t)
+	service.handle(t, execution, 1, "success")
+
+	service.handle(t, execution, 2, "error")
+	service.handle(t, execution, 1, "compensated")
+
+	steps := service.steps(t, execution)
+	if steps[0].Status != entities.StepExecutionCompensated || steps[1].Status != entities.StepExecutionError {
+		t.Fatalf("expected the hotel compensated and the flight failed, got %s and %s", steps[0].Status, steps[1].Status)
+	}
+	saved, err := service.repository.GetSagaExecution(context.Background(), execution.SagaExecutionID)
+	if err != nil {
+		t.Fatalf("error getting the execution: %v", err)
+	}
+	if saved.Status != entities.SagaExecutionFailed {
+		t.Fatalf("expected the execution to fail, got %s", saved.Status)
+	}
+	service.expectSent(t,
+		sentStep{sagaName: "trip-booking", index: 1},
+		sentStep{sagaName: "trip-booking", index: 2},
+		sentStep{sagaName: "trip-booking", index: 1, isCompensation: true},
+	)
+}
+
+func TestStepsFailingTheirCompensationFailTheExecution(t *testing.T) {
+	service := newTestService()
+	execution := service.execute(t)
+	service.handle(t, execution, 1, "success")
+	service.handle(t, execution, 2, "error")
+
+	err := service.HandleStepResult(context.Background(), sagas.StepResultVO{
+		StepIndex:   1,
+		ExecutionID: execution.SagaExecutionID,
+		Result:      "compensation_failed",
+		Error:       &entities.StepError{Code: "TRANSPORT_ERROR", Message: "connection refused"},
+	})
+	if err != nil {
+		t.Fatalf("error handling the compensation failure: %v", err)
+	}
+
+	steps := service.steps(t, execution)
+	if steps[0].Status != entities.StepExecutionError || steps[0].Error == nil {
+		t.Fatalf("expected the hotel to fail with its error, got %s and %v", steps[0].Status, steps[0].Error)
+	}
+	saved, err := service.repository.GetSagaExecution(context.Background(), execution.SagaExecutionID)
+	if err != nil {
+		t.Fatalf("error getting the execution: %v", err)
+	}
+	if saved.Status != entities.SagaExecutionFailed {
+		t.Fatalf("expected the execution to fail, got %s", saved.Status)
+	}
+}
+
+func TestHandleStepResultRefusesResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   sagas.StepResultVO
+		expected error
+	}{
+		{
+			name:     "unknown result",
+			result:   sagas.StepResultVO{StepIndex: 1, Result: "done"},
+			expected: sagas.ErrInvalidStepResult,
+		},
+		{
+			name:     "unstarted step",
+			result:   sagas.StepResultVO{StepIndex: 2, Result: "success"},
+			expected: sagas.ErrUnexpectedStepResult,
+		},
+		{
+			name:     "unknown step",
+			result:   sagas.StepResultVO{StepIndex: 4, Result: "success"},
+			expected: sagas.ErrStepExecutionNotFound,
+		},
+		{
+			name:     "step not in compensation",
+			result:   sagas.StepResultVO{StepIndex: 1, Result: "compensated"},
+			expected: sagas.ErrUnexpectedStepResult,
+		},
+		{
+			name:     "compensation failure of a step not in compensation",
+			result:   sagas.StepResultVO{StepIndex: 1, Result: "compensation_failed"},
+			expected: sagas.ErrUnexpectedStepResult,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := newTestService()
+			execution := service.execute(t)
+
+			test.result.ExecutionID = execution.SagaExecutionID
+			err := service.HandleStepResult(context.Background(), test.result)
+
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, err)
+			}
+			if !sagas.IsPermanentResultError(err) {
+				t.Fatalf("expected %v to be permanent", err)
+			}
+		})
+	}
+}
+
+func TestHandleStepResultOfUnknownExecutions(t *testing.T) {
+	service := newTestService()
+
+	err := service.HandleStepResult(context.Background(), sagas.StepResultVO{
+		StepIndex: 1, ExecutionID: uuid.New(), Result: "success",
+	})
+
+	if !errors.Is(err, sagas.ErrSagaExecutionNotFound) {
+		t.Fatalf("expected ErrSagaExecutionNotFound, got %v", err)
+	}
+}
+
+// encryptingRepository stands for the repositories encrypting the sensitive fields, values starting with `enc:` look
+// encrypted
+type encryptingRepository struct {
+	memory.SagaRepository
+
+	canEncrypt bool
+}
+
+func (r encryptingRepository) CanEncrypt() bool {
+	return r.canEncrypt
+}
+
+func (r encryptingRepository) HasEncryptedValues(document []byte) (bool, error) {
+	return strings.Contains(string(document), `"enc:`), nil
+}
+
+var cardSaga = sagas.CreateSagaVO{
+	Name:    "Card Payment",
+	Payload: []byte(`{"type": "object", "properties": {"card": {"type": "string", "sensitive": true}}}`),
+	Steps:   []sagas.CreateSagaVOSteps{{Name: "Payment"}},
+}
+
+func TestCreateSagaRequiresAnEncryptionKeyForSensitiveFields(t *testing.T) {
+	repository := encryptingRepository{SagaRepository: memory.NewSagaRepository()}
+	service := sagas.NewService(repository, recordingGateway{sent: &[]sentStep{}})
+
+	_, err := service.CreateSaga(context.Background(), cardSaga)
+	if !errors.Is(err, sagas.ErrEncryptionKeyRequired) {
+		t.Fatalf("expected ErrEncryptionKeyRequired, got %v", err)
+	}
+
+	// Sagas without sensitive fields need no key
+	if _, err := service.CreateSaga(context.Background(), tripSaga); err != nil {
+		t.Fatalf("error creating the saga: %v", err)
+	}
+}
+
+func TestEncryptedValuesAreRefused(t *testing.T) {
+	repository := encryptingRepository{SagaRepository: memory.NewSagaRepository(), canEncrypt: true}
+	sent := &[]sentStep{}
+	service := sagas.NewService(repository, recordingGateway{sent: sent})
+	ctx := context.Background()
+	saga, err := service.CreateSaga(ctx, cardSaga)
+	if err != nil {
+		t.Fatalf("error creating the saga: %v", err)
+	}
+
+	_, err = service.CreateSagaExecution(ctx, sagas.CreateSagaExecutionVO{
+		SagaID:  saga.SagaID,
+		Payload: []byte(`{"card": "enc:garbage"}`),
+	})
+	if !errors.Is(err, sagas.ErrEncryptedValue) {
+		t.Fatalf("expected ErrEncryptedValue, got %v", err)
+	}
+
+	execution, err := service.CreateSagaExecution(ctx, sagas.CreateSagaExecutionVO{
+		SagaID:  saga.SagaID,
+		Payload: []byte(`{"card": "4111"}`),
+	})
+	if err != nil {
+		t.Fatalf("error creating the execution: %v", err)
+	}
+	err = service.HandleStepResult(ctx, sagas.StepResultVO{
+		StepIndex:   1,
+		ExecutionID: execution.SagaExecutionID,
+		Result:      "success",
+		Output:      []byte(`{"receipt": "enc:garbage"}`),
+	})
+	if !errors.Is(err, sagas.ErrInvalidStepResult) {
+		t.Fatalf("expected ErrInvalidStepResult, got %v", err)
+	}
+}
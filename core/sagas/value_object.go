@@ -1,18 +1,66 @@
 package sagas
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/thepabloaguilar/sukuna/core/entities"
 )
 
 type CreateSagaVO struct {
-	Name    string
-	Payload []byte
-	Steps   []CreateSagaVOSteps
+	Name          string
+	Description   string
+	Payload       []byte
+	RetentionDays int
+	Steps         []CreateSagaVOSteps
 }
 
 type CreateSagaVOSteps struct {
-	Name string
+	Name                string
+	InputMapping        []byte
+	CompensateOnFailure bool
+	// Transport defaults to kafka
+	Transport       entities.StepTransport
+	TransportConfig []byte
+}
+
+type ListSagasVO struct {
+	Name          string
+	FormattedName string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Deprecated    *bool
+	Cursor        string
+	Limit         int
+}
+
+// SagaFilter is used by the repository to list sagas, nil attributes aren't filtered
+type SagaFilter struct {
+	Name          string
+	FormattedName string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Deprecated    *bool
+	After         *Cursor
+	Limit         int
+}
+
+type SagaVO struct {
+	entities.Saga
+	Steps []entities.SagaStep
+}
+
+type SagaPageVO struct {
+	Sagas      []SagaVO
+	NextCursor string
+}
+
+// UpdateSagaVO changes only the non nil attributes
+type UpdateSagaVO struct {
+	SagaID        uuid.UUID
+	Name          *string
+	Description   *string
+	RetentionDays *int
 }
 
 type CreateSagaExecutionVO struct {
@@ -22,12 +70,91 @@ type CreateSagaExecutionVO struct {
 
 type SagaExecutionVO struct {
 	entities.SagaExecution
-	Steps []entities.StepExecution
+	Steps           []entities.StepExecution
+	SensitiveFields []string
+}
+
+type ListSagaExecutionsVO struct {
+	SagaID        *uuid.UUID
+	Status        entities.SagaExecutionStatus
+	StepStatus    entities.StepExecutionStatus
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Cursor        string
+	Limit         int
+}
+
+// SagaExecutionFilter is used by the repository to list executions, nil and empty attributes aren't filtered
+type SagaExecutionFilter struct {
+	SagaID        *uuid.UUID
+	Status        entities.SagaExecutionStatus
+	StepStatus    entities.StepExecutionStatus
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	After         *Cursor
+	Limit         int
+}
+
+type SagaExecutionPageVO struct {
+	Executions []SagaExecutionVO
+	NextCursor string
 }
 
+// StepResultVO is what workers report, the SagaName they send is only informative since the next steps are always
+// sent to the saga of the execution.
 type StepResultVO struct {
-	SagaName string
-	StepIndex int
+	SagaName    string
+	StepIndex   int
 	ExecutionID uuid.UUID
-	Result string
+	Result      string
+	Output      []byte
+	Error       *entities.StepError
+}
+
+type GetSagaStatisticsVO struct {
+	SagaID uuid.UUID
+	From   *time.Time
+	To     *time.Time
+}
+
+// DurationPercentiles holds the p50, p95 and p99 of the durations, zero when nothing has finished
+type DurationPercentiles struct {
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+}
+
+// ExecutionStatistics aggregates the executions created in a time window.
+// Compensated counts the executions with at least one compensated step.
+type ExecutionStatistics struct {
+	Total        int
+	Running      int
+	Compensating int
+	Succeeded    int
+	Failed       int
+	Compensated  int
+	Duration     DurationPercentiles
+}
+
+// StepStatistics aggregates the started executions of a step, the duration goes from started to finished or error.
+type StepStatistics struct {
+	Index    int
+	Name     string
+	Total    int
+	Failed   int
+	Duration DurationPercentiles
+}
+
+type SagaStatisticsVO struct {
+	SagaID           uuid.UUID
+	From             time.Time
+	To               time.Time
+	Executions       ExecutionStatistics
+	CompensationRate float64
+	Steps            []StepStatisticsVO
+}
+
+type StepStatisticsVO struct {
+	StepStatistics
+	FailureRate float64
 }
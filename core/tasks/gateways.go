@@ -0,0 +1,21 @@
+package tasks
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+)
+
+type Repository interface {
+	CreateTask(ctx context.Context, task entities.Task) error
+	// LeaseTask returns the oldest task of the queues that isn't leased, or whose lease expired, with the given token
+	LeaseTask(ctx context.Context, queues []string, token uuid.UUID, lease time.Duration) (entities.Task, error)
+	// ClaimTask moves the task from the token to the claim token while the lease of the token is valid
+	ClaimTask(ctx context.Context, token uuid.UUID, claimToken uuid.UUID, lease time.Duration) (entities.Task, error)
+	// ReleaseTask gives a claimed task back to the token
+	ReleaseTask(ctx context.Context, claimToken uuid.UUID, token uuid.UUID) error
+	ExtendTaskLease(ctx context.Context, token uuid.UUID, lease time.Duration) (entities.Task, error)
+	DeleteTask(ctx context.Context, taskID uuid.UUID) error
+}
@@ -0,0 +1,140 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+)
+
+const (
+	pollInterval = 500 * time.Millisecond
+	maxWait      = time.Minute
+	defaultLease = 5 * time.Minute
+	// claimLease is the least a task stays leased while its result is handled
+	claimLease = time.Minute
+)
+
+var (
+	ErrNoTask        = errors.New("no task available")
+	ErrTaskNotFound  = errors.New("task not found")
+	ErrInvalidQueues = errors.New("at least one queue is required")
+	ErrInvalidLease  = errors.New("invalid lease")
+)
+
+type Service interface {
+	PollTask(ctx context.Context, vo PollTaskVO) (entities.Task, error)
+	ExtendTaskLease(ctx context.Context, token uuid.UUID, lease time.Duration) (entities.Task, error)
+	CompleteTask(ctx context.Context, vo CompleteTaskVO) error
+}
+
+type service struct {
+	repository  Repository
+	sagaService sagas.Service
+}
+
+func NewService(repository Repository, sagaService sagas.Service) Service {
+	return service{
+		repository:  repository,
+		sagaService: sagaService,
+	}
+}
+
+// PollTask leases the oldest task of the queues, waiting for one, at most a minute, when the queues are empty.
+// Tasks whose lease expired are given to the next worker polling their queue.
+func (svc service) PollTask(ctx context.Context, vo PollTaskVO) (entities.Task, error) {
+	if len(vo.Queues) == 0 {
+		return entities.Task{}, ErrInvalidQueues
+	}
+	lease, err := leaseDuration(vo.Lease)
+	if err != nil {
+		return entities.Task{}, err
+	}
+
+	wait := vo.Wait
+	if wait > maxWait {
+		wait = maxWait
+	}
+	deadline := time.Now().Add(wait)
+
+	for {
+		task, err := svc.repository.LeaseTask(ctx, vo.Queues, uuid.New(), lease)
+		if !errors.Is(err, ErrNoTask) {
+			return task, err
+		}
+		if time.Now().Add(pollInterval).After(deadline) {
+			return entities.Task{}, ErrNoTask
+		}
+
+		select {
+		case <-ctx.Done():
+			return entities.Task{}, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// ExtendTaskLease keeps the task with the worker for longer, for steps taking longer than the lease.
+func (svc service) ExtendTaskLease(ctx context.Context, token uuid.UUID, lease time.Duration) (entities.Task, error) {
+	lease, err := leaseDuration(lease)
+	if err != nil {
+		return entities.Task{}, err
+	}
+
+	return svc.repository.ExtendTaskLease(ctx, token, lease)
+}
+
+// CompleteTask claims the task, so concurrent completions with the same token handle the result once, handles the
+// result and deletes the task. Tokens of expired leases are refused, their tasks may have been given to another worker.
+// When the result can't be handled the task is given back to the token to be completed again, unless the step
+// isn't waiting for it anymore.
+func (svc service) CompleteTask(ctx context.Context, vo CompleteTaskVO) error {
+	claimToken := uuid.New()
+	task, err := svc.repository.ClaimTask(ctx, vo.Token, claimToken, claimLease)
+	if err != nil {
+		return err
+	}
+
+	err = svc.sagaService.HandleStepResult(ctx, sagas.StepResultVO{
+		SagaName:    task.SagaName,
+		StepIndex:   task.StepIndex,
+		ExecutionID: task.SagaExecutionID,
+		Result:      vo.Result,
+		Output:      vo.Output,
+		Error:       vo.Error,
+	})
+	if err != nil && !isStaleTask(err) {
+		if releaseErr := svc.repository.ReleaseTask(ctx, claimToken, vo.Token); releaseErr != nil {
+			return fmt.Errorf("%w, %v", err, releaseErr)
+		}
+		return err
+	}
+
+	if err := svc.repository.DeleteTask(ctx, task.TaskID); err != nil {
+		return fmt.Errorf("error deleting task: %w", err)
+	}
+
+	return err
+}
+
+// isStaleTask tells whether the step of the task isn't waiting for a result anymore, so the task can be deleted.
+func isStaleTask(err error) bool {
+	return errors.Is(err, sagas.ErrUnexpectedStepResult) ||
+		errors.Is(err, sagas.ErrSagaExecutionNotFound) ||
+		errors.Is(err, sagas.ErrStepExecutionNotFound)
+}
+
+func leaseDuration(lease time.Duration) (time.Duration, error) {
+	if lease == 0 {
+		return defaultLease, nil
+	}
+	if lease < time.Second {
+		return 0, ErrInvalidLease
+	}
+
+	return lease, nil
+}
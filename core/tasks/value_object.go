@@ -0,0 +1,23 @@
+package tasks
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+)
+
+type PollTaskVO struct {
+	Queues []string
+	// Wait is how long to wait for a task when the queues are empty
+	Wait time.Duration
+	// Lease is for how long the task isn't given to other workers
+	Lease time.Duration
+}
+
+type CompleteTaskVO struct {
+	Token  uuid.UUID
+	Result string
+	Output []byte
+	Error  *entities.StepError
+}
@@ -0,0 +1,130 @@
+// Package embedded runs sukuna in the same process as the service using it, with its sagas kept in memory and
+// their steps being Go functions. It's meant for tests and small services that don't need Kafka or Postgres.
+package embedded
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/gateways/memory"
+	"github.com/thepabloaguilar/sukuna/gateways/step_execution"
+)
+
+const waitInterval = 10 * time.Millisecond
+
+var ErrStepFunctionRequired = errors.New("step execute function is required")
+
+// anyPayload is the JSON schema of sagas defined without one
+var anyPayload = []byte("{}")
+
+type SagaDefinition struct {
+	Name        string
+	Description string
+	// Schema is the JSON schema of the executions payload, any payload is accepted without it
+	Schema []byte
+	Steps  []StepDefinition
+}
+
+type StepDefinition struct {
+	Name       string
+	Execute    step_execution.StepFunc
+	Compensate step_execution.CompensationFunc
+	// InputMapping builds the step payload from the execution payload and the previous steps outputs
+	InputMapping        []byte
+	CompensateOnFailure bool
+}
+
+// Orchestrator is a `sagas.Service` whose steps run as the Go functions of the saga definitions.
+type Orchestrator struct {
+	sagas.Service
+	functions step_execution.FunctionGateway
+}
+
+func New() Orchestrator {
+	// The function gateway hands the results to the service, which is only created after its gateway
+	var sagaService sagas.Service
+	functions := step_execution.NewFunctionGateway(func(ctx context.Context, result sagas.StepResultVO) error {
+		return sagaService.HandleStepResult(ctx, result)
+	})
+	sagaService = sagas.NewService(memory.NewSagaRepository(), functions)
+
+	return Orchestrator{Service: sagaService, functions: functions}
+}
+
+// DefineSaga creates the saga and registers the functions of its steps, every step needs an Execute function.
+func (o Orchestrator) DefineSaga(ctx context.Context, definition SagaDefinition) (entities.Saga, error) {
+	schema := definition.Schema
+	if len(schema) == 0 {
+		schema = anyPayload
+	}
+
+	vo := sagas.CreateSagaVO{
+		Name:        definition.Name,
+		Description: definition.Description,
+		Payload:     schema,
+		Steps:       make([]sagas.CreateSagaVOSteps, 0, len(definition.Steps)),
+	}
+	for _, step := range definition.Steps {
+		if step.Execute == nil {
+			return entities.Saga{}, fmt.Errorf("%w: step %s", ErrStepFunctionRequired, step.Name)
+		}
+		vo.Steps = append(vo.Steps, sagas.CreateSagaVOSteps{
+			Name:                step.Name,
+			InputMapping:        step.InputMapping,
+			CompensateOnFailure: step.CompensateOnFailure,
+		})
+	}
+
+	saga, err := o.CreateSaga(ctx, vo)
+	if err != nil {
+		return entities.Saga{}, err
+	}
+
+	for _, step := range definition.Steps {
+		o.functions.Register(saga.FormattedName, step.Name, step_execution.StepFunctions{
+			Execute:    step.Execute,
+			Compensate: step.Compensate,
+		})
+	}
+
+	return saga, nil
+}
+
+// Execute starts an execution of the saga, marshaling the payload to JSON.
+func (o Orchestrator) Execute(ctx context.Context, sagaID uuid.UUID, payload interface{}) (entities.SagaExecution, error) {
+	marshaledPayload, err := json.Marshal(payload)
+	if err != nil {
+		return entities.SagaExecution{}, err
+	}
+
+	return o.CreateSagaExecution(ctx, sagas.CreateSagaExecutionVO{SagaID: sagaID, Payload: marshaledPayload})
+}
+
+// Wait returns the execution once it succeeded or failed, or the context error when it ends first.
+// Executions whose compensation failed are kept compensating, so waiting on them needs a context with a deadline.
+func (o Orchestrator) Wait(ctx context.Context, executionID uuid.UUID) (sagas.SagaExecutionVO, error) {
+	ticker := time.NewTicker(waitInterval)
+	defer ticker.Stop()
+
+	for {
+		execution, err := o.GetSagaExecution(ctx, executionID)
+		if err != nil {
+			return sagas.SagaExecutionVO{}, err
+		}
+		if execution.Status == entities.SagaExecutionSucceeded || execution.Status == entities.SagaExecutionFailed {
+			return execution, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return execution, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package embedded
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/gateways/step_execution"
+)
+
+// calls records the steps and compensations run, they run in background
+type calls struct {
+	mutex sync.Mutex
+	names []string
+}
+
+func (c *calls) add(name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.names = append(c.names, name)
+}
+
+func (c *calls) list() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]string(nil), c.names...)
+}
+
+func output(value string) step_execution.StepFunc {
+	return func(_ context.Context, _ json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(value), nil
+	}
+}
+
+func compensation(calls *calls, name string) step_execution.CompensationFunc {
+	return func(_ context.Context, _ json.RawMessage) error {
+		calls.add(name)
+		return nil
+	}
+}
+
+func run(t *testing.T, orchestrator Orchestrator, definition SagaDefinition) sagas.SagaExecutionVO {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	saga, err := orchestrator.DefineSaga(ctx, definition)
+	if err != nil {
+		t.Fatalf("error defining the saga: %v", err)
+	}
+	execution, err := orchestrator.Execute(ctx, saga.SagaID, map[string]string{"customer": "pablo"})
+	if err != nil {
+		t.Fatalf("error executing the saga: %v", err)
+	}
+	result, err := orchestrator.Wait(ctx, execution.SagaExecutionID)
+	if err != nil {
+		t.Fatalf("error waiting the execution: %v", err)
+	}
+
+	return result
+}
+
+func TestExecutionSucceeds(t *testing.T) {
+	var flightPayload json.RawMessage
+	result := run(t, New(), SagaDefinition{
+		Name: "Trip Booking",
+		Steps: []StepDefinition{
+			{Name: "Hotel", Execute: output(`{"booking_id": "h-1"}`)},
+			{
+				Name: "Flight",
+				Execute: func(_ context.Context, payload json.RawMessage) (json.RawMessage, error) {
+					flightPayload = payload
+					return json.RawMessage(`{"booking_id": "f-1"}`), nil
+				},
+				InputMapping: []byte(`{"customer": "{{ /payload/customer }}", "hotel": "{{ /steps/hotel/booking_id }}"}`),
+			},
+		},
+	})
+
+	if result.Status != entities.SagaExecutionSucceeded {
+		t.Fatalf("expected the execution to succeed, got %s", result.Status)
+	}
+	var mapped map[string]string
+	if err := json.Unmarshal(flightPayload, &mapped); err != nil {
+		t.Fatalf("error reading the flight payload %s: %v", flightPayload, err)
+	}
+	if mapped["customer"] != "pablo" || mapped["hotel"] != "h-1" {
+		t.Fatalf("expected the flight payload to be mapped, got %v", mapped)
+	}
+}
+
+func TestFailedStepCompensatesThePreviousSteps(t *testing.T) {
+	compensated := &calls{}
+	result := run(t, New(), SagaDefinition{
+		Name: "Failing Trip",
+		Steps: []StepDefinition{
+			{Name: "Hotel", Execute: output(`{}`), Compensate: compensation(compensated, "hotel")},
+			{
+				Name: "Flight",
+				Execute: func(_ context.Context, _ json.RawMessage) (json.RawMessage, error) {
+					return nil, entities.StepError{Code: "NO_SEATS", Message: "no seats left"}
+				},
+				Compensate: compensation(compensated, "flight"),
+			},
+		},
+	})
+
+	if result.Status != entities.SagaExecutionFailed {
+		t.Fatalf("expected the execution to fail, got %s", result.Status)
+	}
+	if names := compensated.list(); len(names) != 1 || names[0] != "hotel" {
+		t.Fatalf("expected only the hotel to be compensated, got %v", names)
+	}
+	flight := result.Steps[1]
+	if !flight.Failed || flight.Error == nil || flight.Error.Code != "NO_SEATS" {
+		t.Fatalf("expected the flight step to fail with NO_SEATS, got %+v", flight)
+	}
+}
+
+func TestPanickingStepFails(t *testing.T) {
+	result := run(t, New(), SagaDefinition{
+		Name: "Panicking Trip",
+		Steps: []StepDefinition{
+			{
+				Name: "Hotel",
+				Execute: func(_ context.Context, _ json.RawMessage) (json.RawMessage, error) {
+					panic("hotel unavailable")
+				},
+			},
+		},
+	})
+
+	if result.Status != entities.SagaExecutionFailed {
+		t.Fatalf("expected the execution to fail, got %s", result.Status)
+	}
+	hotel := result.Steps[0]
+	if hotel.Error == nil || hotel.Error.Code != step_execution.FunctionStepErrorCode {
+		t.Fatalf("expected the hotel step to fail with %s, got %+v", step_execution.FunctionStepErrorCode, hotel.Error)
+	}
+}
+
+func TestDefineSagaRequiresExecute(t *testing.T) {
+	orchestrator := New()
+
+	_, err := orchestrator.DefineSaga(context.Background(), SagaDefinition{
+		Name:  "Incomplete Trip",
+		Steps: []StepDefinition{{Name: "Hotel"}},
+	})
+
+	if !errors.Is(err, ErrStepFunctionRequired) {
+		t.Fatalf("expected ErrStepFunctionRequired, got %v", err)
+	}
+	sagaPage, err := orchestrator.ListSagas(context.Background(), sagas.ListSagasVO{})
+	if err != nil {
+		t.Fatalf("error listing the sagas: %v", err)
+	}
+	if len(sagaPage.Sagas) != 0 {
+		t.Fatalf("expected the saga not to be created, got %v", sagaPage.Sagas)
+	}
+}
+
+func TestWaitReturnsUnknownExecutions(t *testing.T) {
+	_, err := New().Wait(context.Background(), uuid.New())
+
+	if !errors.Is(err, sagas.ErrSagaExecutionNotFound) {
+		t.Fatalf("expected ErrSagaExecutionNotFound, got %v", err)
+	}
+}
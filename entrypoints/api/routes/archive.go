@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/retention"
+)
+
+func ArchiveRouter(app fiber.Router, service retention.Service, authorizer SensitiveDataAuthorizer) {
+	app.Get("/archived-executions/:executionID", getArchivedExecution(service, authorizer))
+}
+
+func getArchivedExecution(service retention.Service, authorizer SensitiveDataAuthorizer) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		executionID, err := uuid.Parse(ctx.Params("executionID"))
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		execution, err := service.GetArchivedExecution(ctx.Context(), executionID)
+		if err != nil {
+			if errors.Is(err, retention.ErrArchivedExecutionNotFound) {
+				return ctx.Status(fiber.StatusNotFound).
+					JSON(map[string]string{"error": err.Error()})
+			}
+
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		response, err := newSagaExecutionResponse(execution, authorizer(ctx))
+		if err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		return ctx.JSON(response)
+	}
+}
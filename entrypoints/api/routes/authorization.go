@@ -0,0 +1,52 @@
+package routes
+
+import (
+	"crypto/subtle"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	sensitiveDataTokenHeader = "X-Sukuna-Sensitive-Data-Token"
+	workerTokenHeader        = "X-Sukuna-Worker-Token"
+)
+
+// SensitiveDataAuthorizer tells if the caller is allowed to see the sensitive fields of a payload.
+type SensitiveDataAuthorizer func(ctx *fiber.Ctx) bool
+
+// WorkerAuthorizer tells if the caller is a worker allowed to report step results.
+type WorkerAuthorizer func(ctx *fiber.Ctx) bool
+
+// NewTokenAuthorizer authorizes the callers sending the given token in the `X-Sukuna-Sensitive-Data-Token` header.
+// An empty token authorizes nobody.
+func NewTokenAuthorizer(token string) SensitiveDataAuthorizer {
+	return SensitiveDataAuthorizer(headerTokenAuthorizer(sensitiveDataTokenHeader, token))
+}
+
+// NewWorkerTokenAuthorizer authorizes the callers sending the given token in the `X-Sukuna-Worker-Token` header.
+// An empty token authorizes nobody.
+func NewWorkerTokenAuthorizer(token string) WorkerAuthorizer {
+	return WorkerAuthorizer(headerTokenAuthorizer(workerTokenHeader, token))
+}
+
+func headerTokenAuthorizer(header, token string) func(ctx *fiber.Ctx) bool {
+	return func(ctx *fiber.Ctx) bool {
+		if token == "" {
+			return false
+		}
+
+		received := ctx.Get(header)
+		return subtle.ConstantTimeCompare([]byte(received), []byte(token)) == 1
+	}
+}
+
+func requireWorker(authorizer WorkerAuthorizer) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if !authorizer(ctx) {
+			return ctx.Status(fiber.StatusUnauthorized).
+				JSON(map[string]string{"error": "missing or invalid worker token"})
+		}
+
+		return ctx.Next()
+	}
+}
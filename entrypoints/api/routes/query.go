@@ -0,0 +1,67 @@
+package routes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func parseTimeQuery(ctx *fiber.Ctx, key string) (*time.Time, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s, it must be a RFC3339 date: %w", key, err)
+	}
+
+	return &parsed, nil
+}
+
+func parseBoolQuery(ctx *fiber.Ctx, key string) (*bool, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s, it must be a boolean: %w", key, err)
+	}
+
+	return &parsed, nil
+}
+
+func parseIntQuery(ctx *fiber.Ctx, key string) (int, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s, it must be an integer: %w", key, err)
+	}
+
+	return parsed, nil
+}
+
+func parseEnumQuery(ctx *fiber.Ctx, key string, values ...string) (string, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return "", nil
+	}
+
+	for _, allowed := range values {
+		if value == allowed {
+			return value, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid %s, it must be one of %s", key, strings.Join(values, ", "))
+}
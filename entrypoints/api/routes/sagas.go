@@ -7,25 +7,78 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
 	"github.com/thepabloaguilar/sukuna/core/sagas"
 )
 
-func SagaRouter(app fiber.Router, service sagas.Service) {
+func SagaRouter(app fiber.Router, service sagas.Service, authorizer SensitiveDataAuthorizer) {
 	// Sagas
+	app.Get("/sagas", listSagas(service))
 	app.Get("/sagas/:sagaID", getSaga(service))
 	app.Post("/sagas", createSaga(service))
+	app.Patch("/sagas/:sagaID", updateSaga(service))
+	app.Post("/sagas/:sagaID/deprecate", deprecateSaga(service))
+	app.Delete("/sagas/:sagaID", deleteSaga(service))
 
 	// Saga executions
-	app.Get("/sagas/:sagaID/executions/:executionID", getSagaExecution(service))
+	app.Get("/executions", listSagaExecutions(service))
+	app.Get("/sagas/:sagaID/executions", listSagaExecutions(service))
+	app.Get("/sagas/:sagaID/executions/:executionID", getSagaExecution(service, authorizer))
 	app.Post("/sagas/:sagaID/executions", createSagaExecution(service))
+
+	// Saga statistics
+	app.Get("/sagas/:sagaID/statistics", getSagaStatistics(service))
 }
 
 type getSagaResponse struct {
 	SagaID        uuid.UUID       `json:"saga_id"`
 	Name          string          `json:"name"`
 	FormattedName string          `json:"formatted_name"`
-	Payload       json.RawMessage `json:"payload"`
+	Description   string          `json:"description"`
+	Payload       json.RawMessage `json:"payload,omitempty"`
+	RetentionDays int             `json:"retention_days"`
 	CreatedAt     time.Time       `json:"created_at"`
+	DeprecatedAt  *time.Time      `json:"deprecated_at"`
+}
+
+func newSagaResponse(saga entities.Saga) getSagaResponse {
+	return getSagaResponse{
+		SagaID:        saga.SagaID,
+		Name:          saga.Name,
+		FormattedName: saga.FormattedName,
+		Description:   saga.Description,
+		Payload:       saga.Payload,
+		RetentionDays: saga.RetentionDays,
+		CreatedAt:     saga.CreatedAt,
+		DeprecatedAt:  saga.DeprecatedAt,
+	}
+}
+
+// sagaErrorStatus maps the service errors to the response status
+func sagaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, sagas.ErrSagaNotFound),
+		errors.Is(err, sagas.ErrSagaExecutionNotFound),
+		errors.Is(err, sagas.ErrStepExecutionNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, sagas.ErrSagaDeprecated),
+		errors.Is(err, sagas.ErrSagaHasExecutions),
+		errors.Is(err, sagas.ErrUnexpectedStepResult):
+		return fiber.StatusConflict
+	case errors.Is(err, sagas.ErrInvalidJSONSchema),
+		errors.Is(err, sagas.ErrSagaWithoutSteps),
+		errors.Is(err, sagas.ErrEncryptionKeyRequired),
+		errors.Is(err, sagas.ErrEncryptedValue),
+		errors.Is(err, sagas.ErrInvalidInputMapping),
+		errors.Is(err, sagas.ErrUnsupportedTransport),
+		errors.Is(err, sagas.ErrInvalidTransportConfig),
+		errors.Is(err, sagas.ErrInvalidStepResult),
+		errors.Is(err, sagas.ErrInvalidCursor),
+		errors.Is(err, sagas.ErrInvalidTimeWindow):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
 }
 
 func getSaga(service sagas.Service) fiber.Handler {
@@ -40,41 +93,138 @@ func getSaga(service sagas.Service) fiber.Handler {
 
 		saga, err := service.GetSagaByID(ctx.Context(), sagaID)
 		if err != nil {
-			return ctx.Status(fiber.StatusInternalServerError).
+			return ctx.Status(sagaErrorStatus(err)).
 				JSON(map[string]string{"error": err.Error()})
 		}
 
-		response := getSagaResponse{
-			SagaID:        saga.SagaID,
-			Name:          saga.Name,
-			FormattedName: saga.FormattedName,
-			Payload:       saga.Payload,
-			CreatedAt:     saga.CreatedAt,
+		return ctx.JSON(newSagaResponse(saga))
+	}
+}
+
+type listSagasResponse struct {
+	Sagas      []listSagasResponseSaga `json:"sagas"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+}
+
+type listSagasResponseSaga struct {
+	getSagaResponse
+	Steps []listSagasResponseStep `json:"steps"`
+}
+
+type listSagasResponseStep struct {
+	StepID              uuid.UUID       `json:"step_id"`
+	Index               int             `json:"index"`
+	Name                string          `json:"name"`
+	InputMapping        json.RawMessage `json:"input_mapping,omitempty"`
+	CompensateOnFailure bool            `json:"compensate_on_failure"`
+	Transport           string          `json:"transport"`
+	TransportConfig     json.RawMessage `json:"transport_config,omitempty"`
+}
+
+func listSagas(service sagas.Service) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		vo, err := parseListSagasQuery(ctx)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		page, err := service.ListSagas(ctx.Context(), vo)
+		if err != nil {
+			return ctx.Status(sagaErrorStatus(err)).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		response := listSagasResponse{
+			Sagas:      make([]listSagasResponseSaga, 0, len(page.Sagas)),
+			NextCursor: page.NextCursor,
+		}
+		for _, saga := range page.Sagas {
+			responseSaga := listSagasResponseSaga{
+				getSagaResponse: newSagaResponse(saga.Saga),
+				Steps:           make([]listSagasResponseStep, 0, len(saga.Steps)),
+			}
+			for _, step := range saga.Steps {
+				responseSaga.Steps = append(responseSaga.Steps, listSagasResponseStep{
+					StepID:              step.StepID,
+					Index:               step.Index,
+					Name:                step.Name,
+					InputMapping:        step.InputMapping,
+					CompensateOnFailure: step.CompensateOnFailure,
+					Transport:           string(step.Transport),
+					TransportConfig:     step.TransportConfig,
+				})
+			}
+			response.Sagas = append(response.Sagas, responseSaga)
 		}
+
 		return ctx.JSON(response)
 	}
 }
 
+func parseListSagasQuery(ctx *fiber.Ctx) (sagas.ListSagasVO, error) {
+	createdAfter, err := parseTimeQuery(ctx, "created_after")
+	if err != nil {
+		return sagas.ListSagasVO{}, err
+	}
+	createdBefore, err := parseTimeQuery(ctx, "created_before")
+	if err != nil {
+		return sagas.ListSagasVO{}, err
+	}
+	deprecated, err := parseBoolQuery(ctx, "deprecated")
+	if err != nil {
+		return sagas.ListSagasVO{}, err
+	}
+	limit, err := parseIntQuery(ctx, "limit")
+	if err != nil {
+		return sagas.ListSagasVO{}, err
+	}
+
+	return sagas.ListSagasVO{
+		Name:          ctx.Query("name"),
+		FormattedName: ctx.Query("formatted_name"),
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+		Deprecated:    deprecated,
+		Cursor:        ctx.Query("cursor"),
+		Limit:         limit,
+	}, nil
+}
+
 type createSagaRequest struct {
-	Name    string                   `json:"name" validate:"required"`
-	Payload json.RawMessage          `json:"payload" validate:"required"`
-	Steps   []createSagaRequestSteps `json:"steps" validate:"required"`
+	Name          string                   `json:"name" validate:"required"`
+	Description   string                   `json:"description"`
+	Payload       json.RawMessage          `json:"payload" validate:"required"`
+	RetentionDays int                      `json:"retention_days" validate:"min=0"`
+	Steps         []createSagaRequestSteps `json:"steps" validate:"required"`
 }
 
 type createSagaRequestSteps struct {
-	Name string `json:"name" validate:"required"`
+	Name                string          `json:"name" validate:"required"`
+	InputMapping        json.RawMessage `json:"input_mapping"`
+	CompensateOnFailure bool            `json:"compensate_on_failure"`
+	Transport           string          `json:"transport"`
+	TransportConfig     json.RawMessage `json:"transport_config"`
 }
 
 func (p createSagaRequest) toVO() sagas.CreateSagaVO {
 	steps := make([]sagas.CreateSagaVOSteps, 0)
 	for _, step := range p.Steps {
-		steps = append(steps, sagas.CreateSagaVOSteps{Name: step.Name})
+		steps = append(steps, sagas.CreateSagaVOSteps{
+			Name:                step.Name,
+			InputMapping:        step.InputMapping,
+			CompensateOnFailure: step.CompensateOnFailure,
+			Transport:           entities.StepTransport(step.Transport),
+			TransportConfig:     step.TransportConfig,
+		})
 	}
 
 	return sagas.CreateSagaVO{
-		Name:    p.Name,
-		Payload: p.Payload,
-		Steps:   steps,
+		Name:          p.Name,
+		Description:   p.Description,
+		Payload:       p.Payload,
+		RetentionDays: p.RetentionDays,
+		Steps:         steps,
 	}
 }
 
@@ -100,12 +250,7 @@ func createSaga(service sagas.Service) fiber.Handler {
 
 		saga, err := service.CreateSaga(ctx.Context(), payload.toVO())
 		if err != nil {
-			if errors.Is(err, sagas.ErrInvalidJSONSchema) {
-				return ctx.Status(fiber.StatusBadRequest).
-					JSON(map[string]string{"error": err.Error()})
-			}
-
-			return ctx.Status(fiber.StatusInternalServerError).
+			return ctx.Status(sagaErrorStatus(err)).
 				JSON(map[string]string{"error": err.Error()})
 		}
 
@@ -118,19 +263,123 @@ func createSaga(service sagas.Service) fiber.Handler {
 	}
 }
 
+type updateSagaRequest struct {
+	Name          *string `json:"name" validate:"omitempty,min=1"`
+	Description   *string `json:"description"`
+	RetentionDays *int    `json:"retention_days" validate:"omitempty,min=0"`
+}
+
+func updateSaga(service sagas.Service) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		sagaID, err := uuid.Parse(ctx.Params("sagaID"))
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		payload := new(updateSagaRequest)
+		if err := ctx.BodyParser(payload); err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		validationErrors := validateStruct(payload)
+		if len(validationErrors) > 0 {
+			return ctx.Status(fiber.StatusBadRequest).JSON(validationErrors)
+		}
+
+		vo := sagas.UpdateSagaVO{
+			SagaID:        sagaID,
+			Name:          payload.Name,
+			Description:   payload.Description,
+			RetentionDays: payload.RetentionDays,
+		}
+		saga, err := service.UpdateSaga(ctx.Context(), vo)
+		if err != nil {
+			return ctx.Status(sagaErrorStatus(err)).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		return ctx.JSON(newSagaResponse(saga))
+	}
+}
+
+func deprecateSaga(service sagas.Service) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		sagaID, err := uuid.Parse(ctx.Params("sagaID"))
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		saga, err := service.DeprecateSaga(ctx.Context(), sagaID)
+		if err != nil {
+			return ctx.Status(sagaErrorStatus(err)).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		return ctx.JSON(newSagaResponse(saga))
+	}
+}
+
+func deleteSaga(service sagas.Service) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		sagaID, err := uuid.Parse(ctx.Params("sagaID"))
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		if err := service.DeleteSaga(ctx.Context(), sagaID); err != nil {
+			return ctx.Status(sagaErrorStatus(err)).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		return ctx.SendStatus(fiber.StatusNoContent)
+	}
+}
+
 type getSagaExecutionResponse struct {
 	SagaExecutionID uuid.UUID                       `json:"saga_execution_id"`
 	SagaID          uuid.UUID                       `json:"saga_id"`
-	Payload         json.RawMessage                 `json:"payload"`
+	Status          string                          `json:"status"`
+	Payload         json.RawMessage                 `json:"payload,omitempty"`
+	CreatedAt       time.Time                       `json:"created_at"`
+	FinishedAt      *time.Time                      `json:"finished_at"`
 	Steps           []getSagaExecutionResponseSteps `json:"steps"`
 }
 
 type getSagaExecutionResponseSteps struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
+	Name       string             `json:"name"`
+	Status     string             `json:"status"`
+	StartedAt  *time.Time         `json:"started_at"`
+	FinishedAt *time.Time         `json:"finished_at"`
+	Failed     bool               `json:"failed"`
+	Attempt    int                `json:"attempt"`
+	Error      *stepErrorResponse `json:"error,omitempty"`
 }
 
-func getSagaExecution(service sagas.Service) fiber.Handler {
+type stepErrorResponse struct {
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	Retryable bool            `json:"retryable"`
+	Details   json.RawMessage `json:"details,omitempty"`
+}
+
+func newStepErrorResponse(stepError *entities.StepError) *stepErrorResponse {
+	if stepError == nil {
+		return nil
+	}
+
+	return &stepErrorResponse{
+		Code:      stepError.Code,
+		Message:   stepError.Message,
+		Retryable: stepError.Retryable,
+		Details:   stepError.Details,
+	}
+}
+
+func getSagaExecution(service sagas.Service, authorizer SensitiveDataAuthorizer) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
 		stringExecutionID := ctx.Params("executionID")
 
@@ -142,28 +391,150 @@ func getSagaExecution(service sagas.Service) fiber.Handler {
 		}
 
 		execution, err := service.GetSagaExecution(ctx.Context(), executionID)
+		if err != nil {
+			return ctx.Status(sagaErrorStatus(err)).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		response, err := newSagaExecutionResponse(execution, authorizer(ctx))
 		if err != nil {
 			return ctx.Status(fiber.StatusInternalServerError).
 				JSON(map[string]string{"error": err.Error()})
 		}
 
-		response := getSagaExecutionResponse{
-			SagaExecutionID: execution.SagaExecutionID,
-			SagaID:          execution.SagaID,
-			Payload:         execution.Payload,
-			Steps:           make([]getSagaExecutionResponseSteps, 0, len(execution.Steps)),
+		return ctx.JSON(response)
+	}
+}
+
+func newSagaExecutionResponse(
+	execution sagas.SagaExecutionVO,
+	showSensitiveData bool,
+) (getSagaExecutionResponse, error) {
+	payload := execution.Payload
+	if !showSensitiveData {
+		redactedPayload, err := sagas.RedactFields(execution.Payload, execution.SensitiveFields)
+		if err != nil {
+			return getSagaExecutionResponse{}, err
 		}
-		for _, step := range execution.Steps {
-			response.Steps = append(response.Steps, getSagaExecutionResponseSteps{
-				Name:   step.Name,
-				Status: string(step.Status),
-			})
+		payload = redactedPayload
+	}
+
+	response := getSagaExecutionResponse{
+		SagaExecutionID: execution.SagaExecutionID,
+		SagaID:          execution.SagaID,
+		Status:          string(execution.Status),
+		Payload:         payload,
+		CreatedAt:       execution.CreatedAt,
+		FinishedAt:      execution.FinishedAt,
+		Steps:           make([]getSagaExecutionResponseSteps, 0, len(execution.Steps)),
+	}
+	for _, step := range execution.Steps {
+		response.Steps = append(response.Steps, getSagaExecutionResponseSteps{
+			Name:       step.Name,
+			Status:     string(step.Status),
+			StartedAt:  step.StartedAt,
+			FinishedAt: step.FinishedAt,
+			Failed:     step.Failed,
+			Attempt:    step.Attempt,
+			Error:      newStepErrorResponse(step.Error),
+		})
+	}
+
+	return response, nil
+}
+
+type listSagaExecutionsResponse struct {
+	Executions []getSagaExecutionResponse `json:"executions"`
+	NextCursor string                     `json:"next_cursor,omitempty"`
+}
+
+func listSagaExecutions(service sagas.Service) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		vo, err := parseListSagaExecutionsQuery(ctx)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		page, err := service.ListSagaExecutions(ctx.Context(), vo)
+		if err != nil {
+			return ctx.Status(sagaErrorStatus(err)).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		response := listSagaExecutionsResponse{
+			Executions: make([]getSagaExecutionResponse, 0, len(page.Executions)),
+			NextCursor: page.NextCursor,
+		}
+		for _, execution := range page.Executions {
+			// Listed executions have no payload, so there's nothing to redact
+			executionResponse, err := newSagaExecutionResponse(execution, true)
+			if err != nil {
+				return ctx.Status(fiber.StatusInternalServerError).
+					JSON(map[string]string{"error": err.Error()})
+			}
+			response.Executions = append(response.Executions, executionResponse)
 		}
 
 		return ctx.JSON(response)
 	}
 }
 
+// parseListSagaExecutionsQuery reads the saga id from the path, when listing the executions of a saga,
+// or from the `saga_id` query param.
+func parseListSagaExecutionsQuery(ctx *fiber.Ctx) (sagas.ListSagaExecutionsVO, error) {
+	vo := sagas.ListSagaExecutionsVO{Cursor: ctx.Query("cursor")}
+
+	status, err := parseEnumQuery(
+		ctx,
+		"status",
+		string(entities.SagaExecutionRunning),
+		string(entities.SagaExecutionCompensating),
+		string(entities.SagaExecutionSucceeded),
+		string(entities.SagaExecutionFailed),
+	)
+	if err != nil {
+		return sagas.ListSagaExecutionsVO{}, err
+	}
+	vo.Status = entities.SagaExecutionStatus(status)
+
+	stepStatus, err := parseEnumQuery(
+		ctx,
+		"step_status",
+		string(entities.StepExecutionRegistered),
+		string(entities.StepExecutionStarted),
+		string(entities.StepExecutionFinished),
+		string(entities.StepExecutionInCompensation),
+		string(entities.StepExecutionCompensated),
+		string(entities.StepExecutionError),
+	)
+	if err != nil {
+		return sagas.ListSagaExecutionsVO{}, err
+	}
+	vo.StepStatus = entities.StepExecutionStatus(stepStatus)
+
+	stringSagaID := ctx.Params("sagaID", ctx.Query("saga_id"))
+	if stringSagaID != "" {
+		sagaID, err := uuid.Parse(stringSagaID)
+		if err != nil {
+			return sagas.ListSagaExecutionsVO{}, err
+		}
+		vo.SagaID = &sagaID
+	}
+
+	if vo.CreatedAfter, err = parseTimeQuery(ctx, "created_after"); err != nil {
+		return sagas.ListSagaExecutionsVO{}, err
+	}
+	if vo.CreatedBefore, err = parseTimeQuery(ctx, "created_before"); err != nil {
+		return sagas.ListSagaExecutionsVO{}, err
+	}
+	if vo.Limit, err = parseIntQuery(ctx, "limit"); err != nil {
+		return sagas.ListSagaExecutionsVO{}, err
+	}
+
+	return vo, nil
+}
+
 type createSagaExecutionRequest struct {
 	Payload json.RawMessage `json:"payload"`
 }
@@ -201,7 +572,7 @@ func createSagaExecution(service sagas.Service) fiber.Handler {
 		}
 		execution, err := service.CreateSagaExecution(ctx.Context(), vo)
 		if err != nil {
-			return ctx.Status(fiber.StatusInternalServerError).
+			return ctx.Status(sagaErrorStatus(err)).
 				JSON(map[string]string{"error": err.Error()})
 		}
 
@@ -0,0 +1,111 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+)
+
+// durationResponse has the percentiles in milliseconds
+type durationResponse struct {
+	P50 float64 `json:"p50_ms"`
+	P95 float64 `json:"p95_ms"`
+	P99 float64 `json:"p99_ms"`
+}
+
+func newDurationResponse(duration sagas.DurationPercentiles) durationResponse {
+	milliseconds := func(value time.Duration) float64 {
+		return float64(value) / float64(time.Millisecond)
+	}
+	return durationResponse{
+		P50: milliseconds(duration.P50),
+		P95: milliseconds(duration.P95),
+		P99: milliseconds(duration.P99),
+	}
+}
+
+type getSagaStatisticsResponse struct {
+	SagaID     uuid.UUID                        `json:"saga_id"`
+	From       time.Time                        `json:"from"`
+	To         time.Time                        `json:"to"`
+	Executions getSagaStatisticsExecutions      `json:"executions"`
+	Steps      []getSagaStatisticsResponseSteps `json:"steps"`
+}
+
+type getSagaStatisticsExecutions struct {
+	Total            int              `json:"total"`
+	Running          int              `json:"running"`
+	Compensating     int              `json:"compensating"`
+	Succeeded        int              `json:"succeeded"`
+	Failed           int              `json:"failed"`
+	Compensated      int              `json:"compensated"`
+	CompensationRate float64          `json:"compensation_rate"`
+	Duration         durationResponse `json:"duration"`
+}
+
+type getSagaStatisticsResponseSteps struct {
+	Index       int              `json:"index"`
+	Name        string           `json:"name"`
+	Total       int              `json:"total"`
+	Failed      int              `json:"failed"`
+	FailureRate float64          `json:"failure_rate"`
+	Duration    durationResponse `json:"duration"`
+}
+
+func getSagaStatistics(service sagas.Service) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		sagaID, err := uuid.Parse(ctx.Params("sagaID"))
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		vo := sagas.GetSagaStatisticsVO{SagaID: sagaID}
+		if vo.From, err = parseTimeQuery(ctx, "from"); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+		if vo.To, err = parseTimeQuery(ctx, "to"); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		statistics, err := service.GetSagaStatistics(ctx.Context(), vo)
+		if err != nil {
+			return ctx.Status(sagaErrorStatus(err)).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		executions := statistics.Executions
+		response := getSagaStatisticsResponse{
+			SagaID: statistics.SagaID,
+			From:   statistics.From,
+			To:     statistics.To,
+			Executions: getSagaStatisticsExecutions{
+				Total:            executions.Total,
+				Running:          executions.Running,
+				Compensating:     executions.Compensating,
+				Succeeded:        executions.Succeeded,
+				Failed:           executions.Failed,
+				Compensated:      executions.Compensated,
+				CompensationRate: statistics.CompensationRate,
+				Duration:         newDurationResponse(executions.Duration),
+			},
+			Steps: make([]getSagaStatisticsResponseSteps, 0, len(statistics.Steps)),
+		}
+		for _, step := range statistics.Steps {
+			response.Steps = append(response.Steps, getSagaStatisticsResponseSteps{
+				Index:       step.Index,
+				Name:        step.Name,
+				Total:       step.Total,
+				Failed:      step.Failed,
+				FailureRate: step.FailureRate,
+				Duration:    newDurationResponse(step.Duration),
+			})
+		}
+
+		return ctx.JSON(response)
+	}
+}
@@ -0,0 +1,79 @@
+package routes
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+)
+
+// StepResultRouter receives the results of workers reporting over HTTP, only callers authorized as workers are allowed.
+func StepResultRouter(app fiber.Router, service sagas.Service, authorizer WorkerAuthorizer) {
+	app.Post("/executions/:executionID/steps/:index/result", requireWorker(authorizer), reportStepResult(service))
+}
+
+// reportStepResultRequest is the same result workers send to the `sukuna-out` topic,
+// the execution and the step index come from the path and the saga from the execution.
+type reportStepResultRequest struct {
+	Result string                        `json:"result" validate:"required,oneof=success error compensated"`
+	Output json.RawMessage               `json:"output"`
+	Error  *reportStepResultRequestError `json:"error"`
+}
+
+type reportStepResultRequestError struct {
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	Retryable bool            `json:"retryable"`
+	Details   json.RawMessage `json:"details"`
+}
+
+func reportStepResult(service sagas.Service) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		executionID, err := uuid.Parse(ctx.Params("executionID"))
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+		stepIndex, err := strconv.Atoi(ctx.Params("index"))
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		payload := new(reportStepResultRequest)
+		if err := ctx.BodyParser(payload); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		validationErrors := validateStruct(payload)
+		if len(validationErrors) > 0 {
+			return ctx.Status(fiber.StatusBadRequest).JSON(validationErrors)
+		}
+
+		vo := sagas.StepResultVO{
+			StepIndex:   stepIndex,
+			ExecutionID: executionID,
+			Result:      payload.Result,
+			Output:      payload.Output,
+		}
+		if payload.Error != nil {
+			vo.Error = &entities.StepError{
+				Code:      payload.Error.Code,
+				Message:   payload.Error.Message,
+				Retryable: payload.Error.Retryable,
+				Details:   payload.Error.Details,
+			}
+		}
+
+		if err := service.HandleStepResult(ctx.Context(), vo); err != nil {
+			return ctx.Status(sagaErrorStatus(err)).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		return ctx.SendStatus(fiber.StatusAccepted)
+	}
+}
@@ -0,0 +1,193 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/tasks"
+)
+
+func TaskRouter(app fiber.Router, service tasks.Service, authorizer WorkerAuthorizer) {
+	app.Post("/tasks/poll", requireWorker(authorizer), pollTask(service))
+	app.Post("/tasks/:token/heartbeat", requireWorker(authorizer), extendTaskLease(service))
+	app.Post("/tasks/:token/result", requireWorker(authorizer), completeTask(service))
+}
+
+// pollTaskRequest durations are Go durations, e.g. `20s`
+type pollTaskRequest struct {
+	Queues []string `json:"queues" validate:"required,min=1"`
+	Wait   string   `json:"wait"`
+	Lease  string   `json:"lease"`
+}
+
+type extendTaskLeaseRequest struct {
+	Lease string `json:"lease"`
+}
+
+// completeTaskRequest is the result workers send to the `sukuna-out` topic, the task knows its saga and step.
+type completeTaskRequest struct {
+	Result string                        `json:"result" validate:"required,oneof=success error compensated"`
+	Output json.RawMessage               `json:"output"`
+	Error  *reportStepResultRequestError `json:"error"`
+}
+
+type taskResponse struct {
+	TaskToken   uuid.UUID       `json:"task_token"`
+	Queue       string          `json:"queue"`
+	LeasedUntil *time.Time      `json:"leased_until"`
+	Step        json.RawMessage `json:"step"`
+}
+
+func newTaskResponse(task entities.Task) taskResponse {
+	return taskResponse{
+		TaskToken:   task.Token,
+		Queue:       task.Queue,
+		LeasedUntil: task.LeasedUntil,
+		Step:        task.Step,
+	}
+}
+
+func taskErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, tasks.ErrTaskNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, tasks.ErrInvalidQueues), errors.Is(err, tasks.ErrInvalidLease):
+		return fiber.StatusBadRequest
+	default:
+		return sagaErrorStatus(err)
+	}
+}
+
+func parseDuration(key, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s, it must be a duration: %w", key, err)
+	}
+
+	return parsed, nil
+}
+
+func pollTask(service tasks.Service) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		payload := new(pollTaskRequest)
+		if err := ctx.BodyParser(payload); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		validationErrors := validateStruct(payload)
+		if len(validationErrors) > 0 {
+			return ctx.Status(fiber.StatusBadRequest).JSON(validationErrors)
+		}
+
+		wait, err := parseDuration("wait", payload.Wait)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+		lease, err := parseDuration("lease", payload.Lease)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		task, err := service.PollTask(ctx.Context(), tasks.PollTaskVO{
+			Queues: payload.Queues,
+			Wait:   wait,
+			Lease:  lease,
+		})
+		if errors.Is(err, tasks.ErrNoTask) {
+			return ctx.SendStatus(fiber.StatusNoContent)
+		}
+		if err != nil {
+			return ctx.Status(taskErrorStatus(err)).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		return ctx.JSON(newTaskResponse(task))
+	}
+}
+
+func extendTaskLease(service tasks.Service) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		token, err := uuid.Parse(ctx.Params("token"))
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		// The body is optional, without it the task gets the default lease
+		payload := new(extendTaskLeaseRequest)
+		if len(ctx.Body()) > 0 {
+			if err := ctx.BodyParser(payload); err != nil {
+				return ctx.Status(fiber.StatusBadRequest).
+					JSON(map[string]string{"error": err.Error()})
+			}
+		}
+
+		lease, err := parseDuration("lease", payload.Lease)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		task, err := service.ExtendTaskLease(ctx.Context(), token, lease)
+		if err != nil {
+			return ctx.Status(taskErrorStatus(err)).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		return ctx.JSON(newTaskResponse(task))
+	}
+}
+
+func completeTask(service tasks.Service) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		token, err := uuid.Parse(ctx.Params("token"))
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		payload := new(completeTaskRequest)
+		if err := ctx.BodyParser(payload); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		validationErrors := validateStruct(payload)
+		if len(validationErrors) > 0 {
+			return ctx.Status(fiber.StatusBadRequest).JSON(validationErrors)
+		}
+
+		vo := tasks.CompleteTaskVO{
+			Token:  token,
+			Result: payload.Result,
+			Output: payload.Output,
+		}
+		if payload.Error != nil {
+			vo.Error = &entities.StepError{
+				Code:      payload.Error.Code,
+				Message:   payload.Error.Message,
+				Retryable: payload.Error.Retryable,
+				Details:   payload.Error.Details,
+			}
+		}
+
+		if err := service.CompleteTask(ctx.Context(), vo); err != nil {
+			return ctx.Status(taskErrorStatus(err)).
+				JSON(map[string]string{"error": err.Error()})
+		}
+
+		return ctx.SendStatus(fiber.StatusAccepted)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/thepabloaguilar/sukuna/config"
+	"github.com/thepabloaguilar/sukuna/core/retention"
+	"github.com/thepabloaguilar/sukuna/gateways/archive"
+	"github.com/thepabloaguilar/sukuna/gateways/postgres"
+)
+
+const archiveInterval = time.Hour
+
+func main() {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	interruptChannel := make(chan os.Signal, 1)
+	signal.Notify(interruptChannel, os.Interrupt, os.Kill)
+	defer func() {
+		signal.Stop(interruptChannel)
+		cancel()
+	}()
+
+	go func() {
+		select {
+		case <-interruptChannel:
+			log.Println("interrupt signal received")
+			cancel()
+		case <-ctx.Done():
+		}
+		<-interruptChannel
+		os.Exit(1)
+	}()
+
+	databaseConnection, err := config.CreateDatabaseConnection(ctx)
+	if err != nil {
+		log.Fatalf("error getting db connection: %v", err)
+	}
+	database := postgres.New(databaseConnection)
+
+	keyring, err := config.CreateKeyring()
+	if err != nil {
+		log.Fatalf("error creating the encryption keyring: %v", err)
+	}
+	claimCheck, err := config.CreateClaimCheck(keyring)
+	if err != nil {
+		log.Fatalf("error creating the payload claim check: %v", err)
+	}
+	executionArchive, err := archive.NewFileArchive(getArchiveDirectory(), keyring)
+	if err != nil {
+		log.Fatalf("error creating the execution archive: %v", err)
+	}
+
+	sagasRepository := postgres.NewSagaRepository(*database, keyring, claimCheck)
+	retentionService := retention.NewService(sagasRepository, executionArchive)
+
+	ticker := time.NewTicker(archiveInterval)
+	defer ticker.Stop()
+
+	for {
+		archiveExpiredExecutions(ctx, retentionService)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func archiveExpiredExecutions(ctx context.Context, retentionService retention.Service) {
+	archived, err := retentionService.ArchiveExpiredExecutions(ctx)
+	if err != nil {
+		log.Printf("error archiving executions: %v", err)
+	}
+
+	log.Printf("%d executions archived", archived)
+}
+
+// getArchiveDirectory reads where the finished executions are archived from `SUKUNA_ARCHIVE_DIRECTORY`.
+func getArchiveDirectory() string {
+	if directory := os.Getenv("SUKUNA_ARCHIVE_DIRECTORY"); directory != "" {
+		return directory
+	}
+
+	return "archive"
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"github.com/Shopify/sarama"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/gateways/step_execution"
+)
+
+// laneBuffer is how many results wait for each lane before the partition stops being read
+const laneBuffer = 16
+
+type laneMessage struct {
+	message *sarama.ConsumerMessage
+	result  sagas.StepResultVO
+	headers step_execution.KafkaHeaders
+}
+
+type laneHandler func(message *sarama.ConsumerMessage, result sagas.StepResultVO, headers step_execution.KafkaHeaders) error
+
+// lanes handle the results of a partition concurrently. The results of an execution always go to the same lane,
+// so they're handled in the order they were sent.
+type lanes struct {
+	channels  []chan laneMessage
+	errors    chan error
+	waitGroup sync.WaitGroup
+}
+
+func startLanes(concurrency int, tracker *offsetTracker, handler laneHandler) *lanes {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	l := &lanes{
+		channels: make([]chan laneMessage, concurrency),
+		errors:   make(chan error, concurrency),
+	}
+	for index := range l.channels {
+		l.channels[index] = make(chan laneMessage, laneBuffer)
+		l.waitGroup.Add(1)
+		go l.run(l.channels[index], tracker, handler)
+	}
+
+	return l
+}
+
+// run handles the results of the lane until it's stopped. After an error the lane only drains its results,
+// which aren't marked and are consumed again once the session restarts.
+func (l *lanes) run(channel chan laneMessage, tracker *offsetTracker, handler laneHandler) {
+	defer l.waitGroup.Done()
+
+	failed := false
+	for message := range channel {
+		if failed {
+			continue
+		}
+
+		if err := handler(message.message, message.result, message.headers); err != nil {
+			failed = true
+			l.errors <- err
+			continue
+		}
+		tracker.complete(message.message.Offset)
+	}
+}
+
+func (l *lanes) dispatch(message laneMessage) {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(message.result.ExecutionID.String()))
+
+	l.channels[hash.Sum32()%uint32(len(l.channels))] <- message
+}
+
+// stop waits for the lanes to handle their results, returning the first error of them.
+func (l *lanes) stop() error {
+	for _, channel := range l.channels {
+		close(channel)
+	}
+	l.waitGroup.Wait()
+
+	select {
+	case err := <-l.errors:
+		return err
+	default:
+		return nil
+	}
+}
+
+// offsetTracker marks the offset of the partition once every result before it is handled, as the lanes complete
+// them out of order.
+type offsetTracker struct {
+	mutex     sync.Mutex
+	session   sarama.ConsumerGroupSession
+	topic     string
+	partition int32
+	pending   []int64
+	completed map[int64]bool
+}
+
+func newOffsetTracker(session sarama.ConsumerGroupSession, topic string, partition int32) *offsetTracker {
+	return &offsetTracker{
+		session:   session,
+		topic:     topic,
+		partition: partition,
+		completed: make(map[int64]bool),
+	}
+}
+
+// add registers the offset as being handled, offsets must be added in the order they're consumed.
+func (t *offsetTracker) add(offset int64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.pending = append(t.pending, offset)
+}
+
+func (t *offsetTracker) complete(offset int64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.completed[offset] = true
+	marked := int64(-1)
+	for len(t.pending) > 0 && t.completed[t.pending[0]] {
+		marked = t.pending[0]
+		delete(t.completed, marked)
+		t.pending = t.pending[1:]
+	}
+
+	if marked >= 0 {
+		// The marked offset is the next one to be consumed
+		t.session.MarkOffset(t.topic, t.partition, marked+1, "")
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/Shopify/sarama"
+)
+
+// transactions handle the results in kafka transactions, so the steps they send and the offsets of their messages are
+// committed together and each step is sent exactly once. The producer runs one transaction at a time.
+type transactions struct {
+	mutex    sync.Mutex
+	producer sarama.SyncProducer
+	groupID  string
+}
+
+// run calls handle within a transaction, committing the offset of the message along with it when there's one.
+// The transaction is aborted when any of them fails, so the steps sent by handle are never read by the workers.
+func (t *transactions) run(message *sarama.ConsumerMessage, handle func() error) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if err := t.producer.BeginTxn(); err != nil {
+		return fmt.Errorf("error beginning the transaction: %w", err)
+	}
+
+	err := handle()
+	if err == nil && message != nil {
+		if err = t.producer.AddMessageToTxn(message, t.groupID, nil); err != nil {
+			err = fmt.Errorf("error adding the offset to the transaction: %w", err)
+		}
+	}
+	if err == nil {
+		if err = t.producer.CommitTxn(); err != nil {
+			err = fmt.Errorf("error committing the transaction: %w", err)
+		}
+	}
+	if err != nil {
+		t.abort()
+		return err
+	}
+
+	return nil
+}
+
+func (t *transactions) abort() {
+	if t.producer.TxnStatus()&sarama.ProducerTxnFlagFatalError != 0 {
+		log.Fatalf("the transactional producer can't be used anymore")
+	}
+
+	if err := t.producer.AbortTxn(); err != nil {
+		log.Printf("error aborting the transaction: %v", err)
+	}
+}
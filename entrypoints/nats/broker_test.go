@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/core/sagas/sagastest"
+	"github.com/thepabloaguilar/sukuna/gateways/step_execution"
+)
+
+// newTestSubscription subscribes to the results published from now on through a consumer of its own, it needs a
+// NATS server with JetStream at `SUKUNA_NATS_URL`.
+func newTestSubscription(t *testing.T, options ...nats.SubOpt) (nats.JetStreamContext, *nats.Subscription, string) {
+	natsURL := os.Getenv("SUKUNA_NATS_URL")
+	if natsURL == "" {
+		t.Skip("SUKUNA_NATS_URL isn't set")
+	}
+
+	connection, err := nats.Connect(natsURL)
+	if err != nil {
+		t.Skipf("nats isn't available: %v", err)
+	}
+	t.Cleanup(connection.Close)
+
+	jetStream, err := connection.JetStream()
+	if err != nil {
+		t.Fatalf("error getting the jetstream context: %v", err)
+	}
+	if err := step_execution.EnsureNATSStreams(jetStream); err != nil {
+		t.Fatalf("error creating the streams: %v", err)
+	}
+
+	durable := fmt.Sprintf("sukuna-test-%s", uuid.NewString())
+	options = append([]nats.SubOpt{nats.DeliverNew(), nats.AckExplicit()}, options...)
+	subscription, err := jetStream.PullSubscribe(step_execution.NATSResultSubject, durable, options...)
+	if err != nil {
+		t.Fatalf("error subscribing to the results: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = subscription.Unsubscribe()
+		_ = jetStream.DeleteConsumer(step_execution.NATSResultsStream, durable)
+	})
+
+	return jetStream, subscription, durable
+}
+
+func publishAndFetch(
+	t *testing.T,
+	jetStream nats.JetStreamContext,
+	subscription *nats.Subscription,
+	data []byte,
+) *nats.Msg {
+	if _, err := jetStream.Publish(step_execution.NATSResultSubject, data); err != nil {
+		t.Fatalf("error publishing the result: %v", err)
+	}
+
+	return fetch(t, subscription)
+}
+
+func fetch(t *testing.T, subscription *nats.Subscription) *nats.Msg {
+	messages, err := subscription.Fetch(1, nats.MaxWait(5*time.Second))
+	if err != nil {
+		t.Fatalf("error fetching the result: %v", err)
+	}
+
+	return messages[0]
+}
+
+func numDelivered(t *testing.T, message *nats.Msg) uint64 {
+	metadata, err := message.Metadata()
+	if err != nil {
+		t.Fatalf("error reading the message metadata: %v", err)
+	}
+
+	return metadata.NumDelivered
+}
+
+func newTestHandler(service *sagastest.ResultService) Handler {
+	return Handler{ctx: context.Background(), maxDeliveries: maxDeliveries, SagaService: service}
+}
+
+// waitAcknowledged waits for the consumer to have no message waiting for an acknowledgement.
+func waitAcknowledged(t *testing.T, jetStream nats.JetStreamContext, durable string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		info, err := jetStream.ConsumerInfo(step_execution.NATSResultsStream, durable)
+		if err != nil {
+			t.Fatalf("error getting the consumer info: %v", err)
+		}
+		if info.NumAckPending == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the result to be acknowledged, %d pending", info.NumAckPending)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func stepResult(t *testing.T) []byte {
+	data, err := json.Marshal(step_execution.StepResult{
+		StepIndex:   1,
+		ExecutionID: uuid.New(),
+		Result:      "success",
+		Output:      json.RawMessage(`{"booking_id":"42"}`),
+	})
+	if err != nil {
+		t.Fatalf("error marshaling the result: %v", err)
+	}
+
+	return data
+}
+
+func TestHandleAcknowledgesHandledResults(t *testing.T) {
+	jetStream, subscription, durable := newTestSubscription(t)
+	service := sagastest.NewResultService(nil)
+
+	newTestHandler(service).Handle(publishAndFetch(t, jetStream, subscription, stepResult(t)))
+
+	if results := service.Results(); len(results) != 1 || results[0].Result != "success" {
+		t.Fatalf("expected the result to be handled, got %v", results)
+	}
+	waitAcknowledged(t, jetStream, durable)
+}
+
+func TestHandleRedeliversTransientFailuresAfterADelay(t *testing.T) {
+	jetStream, subscription, durable := newTestSubscription(t)
+	service := sagastest.NewResultService(errors.New("database unavailable"))
+	handler := newTestHandler(service)
+
+	handler.Handle(publishAndFetch(t, jetStream, subscription, stepResult(t)))
+	failedAt := time.Now()
+
+	service.Fail(nil)
+	message := fetch(t, subscription)
+	if delivered := numDelivered(t, message); delivered != 2 {
+		t.Fatalf("expected the result to be redelivered, delivered %d times", delivered)
+	}
+	if elapsed := time.Since(failedAt); elapsed < redeliveryInitialDelay {
+		t.Fatalf("expected the result to be redelivered after %s, got %s", redeliveryInitialDelay, elapsed)
+	}
+
+	handler.Handle(message)
+	if results := service.Results(); len(results) != 2 {
+		t.Fatalf("expected the result to be handled twice, got %d", len(results))
+	}
+	waitAcknowledged(t, jetStream, durable)
+}
+
+func TestHandleTerminatesTransientFailuresOnTheirLastDelivery(t *testing.T) {
+	jetStream, subscription, durable := newTestSubscription(t, nats.MaxDeliver(2))
+	service := sagastest.NewResultService(errors.New("database unavailable"))
+	handler := newTestHandler(service)
+	handler.maxDeliveries = 2
+
+	handler.Handle(publishAndFetch(t, jetStream, subscription, stepResult(t)))
+	message := fetch(t, subscription)
+	if delivered := numDelivered(t, message); delivered != 2 {
+		t.Fatalf("expected the result to be redelivered, delivered %d times", delivered)
+	}
+	handler.Handle(message)
+
+	waitAcknowledged(t, jetStream, durable)
+	if messages, err := subscription.Fetch(1, nats.MaxWait(2*redeliveryInitialDelay)); err == nil {
+		t.Fatalf("expected the result not to be redelivered, got %d messages", len(messages))
+	}
+	if results := service.Results(); len(results) != 2 {
+		t.Fatalf("expected the result to be handled twice, got %d", len(results))
+	}
+}
+
+func TestHandleTerminatesPermanentFailures(t *testing.T) {
+	jetStream, subscription, durable := newTestSubscription(t)
+	service := sagastest.NewResultService(sagas.ErrSagaExecutionNotFound)
+
+	newTestHandler(service).Handle(publishAndFetch(t, jetStream, subscription, stepResult(t)))
+
+	if results := service.Results(); len(results) != 1 {
+		t.Fatalf("expected the result to be handled once, got %d", len(results))
+	}
+	waitAcknowledged(t, jetStream, durable)
+}
+
+func TestHandleTerminatesUnreadableResults(t *testing.T) {
+	jetStream, subscription, durable := newTestSubscription(t)
+	service := sagastest.NewResultService(nil)
+
+	newTestHandler(service).Handle(publishAndFetch(t, jetStream, subscription, []byte("not json")))
+
+	if results := service.Results(); len(results) != 0 {
+		t.Fatalf("expected the result not to be handled, got %v", results)
+	}
+	waitAcknowledged(t, jetStream, durable)
+}
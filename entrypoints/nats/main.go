@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/thepabloaguilar/sukuna/config"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/gateways/postgres"
+	"github.com/thepabloaguilar/sukuna/gateways/step_execution"
+)
+
+const (
+	consumerName   = "sukuna-worker"
+	fetchBatchSize = 10
+	fetchMaxWait   = 5 * time.Second
+
+	// Results failing for a transient reason are redelivered with a backoff, kept below ackWait so the server doesn't
+	// redeliver them first, up to maxDeliveries times
+	ackWait                = time.Minute
+	maxDeliveries          = 10
+	redeliveryInitialDelay = time.Second
+	redeliveryMaxDelay     = 30 * time.Second
+)
+
+func main() {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	interruptChannel := make(chan os.Signal, 1)
+	signal.Notify(interruptChannel, os.Interrupt, os.Kill)
+	defer func() {
+		signal.Stop(interruptChannel)
+		cancel()
+	}()
+
+	go func() {
+		select {
+		case <-interruptChannel:
+			log.Println("interrupt signal received")
+			cancel()
+		case <-ctx.Done():
+		}
+		<-interruptChannel
+		os.Exit(1)
+	}()
+
+	databaseConnection, err := config.CreateDatabaseConnection(ctx)
+	if err != nil {
+		log.Fatalf("error getting db connection: %v", err)
+	}
+	database := postgres.New(databaseConnection)
+
+	kafkaProducer, kafkaConfig, err := config.CreateKafkaProducer()
+	if err != nil {
+		log.Fatalf("error creating the kafka producer: %v", err)
+	}
+	if kafkaProducer != nil {
+		defer func() {
+			if err := kafkaProducer.Close(); err != nil {
+				log.Fatalf("error closing the producer: %v", err)
+			}
+		}()
+	}
+
+	keyring, err := config.CreateKeyring()
+	if err != nil {
+		log.Fatalf("error creating the encryption keyring: %v", err)
+	}
+	claimCheck, err := config.CreateClaimCheck(keyring)
+	if err != nil {
+		log.Fatalf("error creating the payload claim check: %v", err)
+	}
+
+	jetStream, err := createJetStream()
+	if err != nil {
+		log.Fatalf("error connecting to jetstream: %v", err)
+	}
+
+	sagaService, err := config.CreateSagaService(
+		postgres.NewSagaRepository(*database, keyring, claimCheck),
+		postgres.NewTaskRepository(*database, keyring),
+		claimCheck,
+		config.SagaServiceOptions{
+			Context:       ctx,
+			KafkaProducer: kafkaProducer,
+			KafkaConfig:   kafkaConfig,
+			JetStream:     jetStream,
+		},
+	)
+	if err != nil {
+		log.Fatalf("error creating the saga service: %v", err)
+	}
+
+	subscription, err := consume(ctx, jetStream, sagaService)
+	if err != nil {
+		log.Fatalf("error consuming the results: %v", err)
+	}
+	defer func() {
+		if err := subscription.Drain(); err != nil {
+			log.Printf("error draining the subscription: %v", err)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+	}
+}
+
+// consume pulls the results through the `sukuna-worker` durable consumer, shared by every worker replica.
+// Messages are acknowledged only after being handled, so a result is redelivered when the worker stops in the middle of it.
+// The ack wait and the max deliveries only apply when the consumer is created, an existing one keeps its own.
+func consume(
+	ctx context.Context,
+	jetStream nats.JetStreamContext,
+	sagaService sagas.Service,
+) (*nats.Subscription, error) {
+	subscription, err := jetStream.PullSubscribe(
+		step_execution.NATSResultSubject,
+		consumerName,
+		nats.DeliverAll(),
+		nats.AckExplicit(),
+		nats.AckWait(ackWait),
+		nats.MaxDeliver(maxDeliveries),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error subscribing to the results: %w", err)
+	}
+
+	handler := Handler{ctx: ctx, maxDeliveries: maxDeliveries, SagaService: sagaService}
+	go func() {
+		for ctx.Err() == nil {
+			messages, err := subscription.Fetch(fetchBatchSize, nats.MaxWait(fetchMaxWait))
+			if errors.Is(err, nats.ErrTimeout) {
+				continue
+			}
+			if err != nil {
+				log.Printf("error fetching results: %v", err)
+				continue
+			}
+
+			for _, message := range messages {
+				handler.Handle(message)
+			}
+		}
+	}()
+
+	return subscription, nil
+}
+
+// createJetStream connects to the NATS server at `SUKUNA_NATS_URL` and creates the sukuna streams.
+func createJetStream() (nats.JetStreamContext, error) {
+	natsURL := os.Getenv("SUKUNA_NATS_URL")
+	if natsURL == "" {
+		return nil, errors.New("SUKUNA_NATS_URL is required")
+	}
+
+	jetStream, err := config.CreateJetStream(natsURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := step_execution.EnsureNATSStreams(jetStream); err != nil {
+		return nil, err
+	}
+
+	return jetStream, nil
+}
+
+type Handler struct {
+	ctx context.Context
+	// maxDeliveries is the max deliveries of the consumer, the result is terminated on its last delivery
+	maxDeliveries uint64
+
+	SagaService sagas.Service
+}
+
+// Handle acknowledges the message once handled. Results failing for a transient reason are negatively acknowledged
+// after a backoff to be redelivered, until they were delivered h.maxDeliveries times. Results that can't be read or can
+// never be handled are terminated to not be redelivered.
+func (h Handler) Handle(message *nats.Msg) {
+	vo, err := step_execution.UnmarshalStepResult(message.Data)
+	if err != nil {
+		log.Printf("error unmarshaling result: %v\n", err)
+		if err := message.Term(); err != nil {
+			log.Printf("error terminating the message: %v", err)
+		}
+		return
+	}
+
+	log.Printf("result received: %v\n", vo)
+	err = h.SagaService.HandleStepResult(h.ctx, vo)
+	switch {
+	case err == nil:
+		err = message.Ack()
+	case sagas.IsPermanentResultError(err):
+		log.Printf("error handling the result: %v", err)
+		err = message.Term()
+	default:
+		err = h.nakWithDelay(message, err)
+	}
+	if err != nil {
+		log.Printf("error acknowledging the message: %v", err)
+	}
+}
+
+// nakWithDelay negatively acknowledges the message once its redelivery delay passed, or terminates it once it was
+// delivered h.maxDeliveries times. nats.go v1.11.0 has no NakWithDelay and servers before 2.7 ignore its delay, so the
+// message is kept unacknowledged until then.
+func (h Handler) nakWithDelay(message *nats.Msg, cause error) error {
+	metadata, err := message.Metadata()
+	if err != nil {
+		return err
+	}
+	if metadata.NumDelivered >= h.maxDeliveries {
+		log.Printf("error handling the result, giving up after %d deliveries: %v", metadata.NumDelivered, cause)
+		return message.Term()
+	}
+
+	delay := redeliveryDelay(metadata.NumDelivered)
+	log.Printf("error handling the result, it will be redelivered in %s: %v", delay, cause)
+	time.AfterFunc(delay, func() {
+		if err := message.Nak(); err != nil {
+			log.Printf("error acknowledging the message: %v", err)
+		}
+	})
+
+	return nil
+}
+
+// redeliveryDelay doubles the delay with every delivery, up to redeliveryMaxDelay.
+func redeliveryDelay(deliveries uint64) time.Duration {
+	delay := redeliveryInitialDelay
+	for i := uint64(1); i < deliveries && delay < redeliveryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > redeliveryMaxDelay {
+		return redeliveryMaxDelay
+	}
+
+	return delay
+}
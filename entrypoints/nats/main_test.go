@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRedeliveryDelay(t *testing.T) {
+	tests := []struct {
+		deliveries uint64
+		delay      time.Duration
+	}{
+		{deliveries: 1, delay: time.Second},
+		{deliveries: 2, delay: 2 * time.Second},
+		{deliveries: 5, delay: 16 * time.Second},
+		{deliveries: 6, delay: redeliveryMaxDelay},
+		{deliveries: 100, delay: redeliveryMaxDelay},
+	}
+
+	for _, test := range tests {
+		if delay := redeliveryDelay(test.deliveries); delay != test.delay {
+			t.Fatalf("expected %s after %d deliveries, got %s", test.delay, test.deliveries, delay)
+		}
+	}
+}
+
+func TestRedeliveryDelayIsBelowTheAckWait(t *testing.T) {
+	if redeliveryMaxDelay >= ackWait {
+		t.Fatalf("expected the max redelivery delay to be below the ack wait %s, got %s", ackWait, redeliveryMaxDelay)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/streadway/amqp"
+	"github.com/thepabloaguilar/sukuna/config"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/gateways/postgres"
+	"github.com/thepabloaguilar/sukuna/gateways/step_execution"
+	"github.com/wagslane/go-rabbitmq"
+)
+
+const consumerName = "sukuna-worker"
+
+func main() {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	interruptChannel := make(chan os.Signal, 1)
+	signal.Notify(interruptChannel, os.Interrupt, os.Kill)
+	defer func() {
+		signal.Stop(interruptChannel)
+		cancel()
+	}()
+
+	go func() {
+		select {
+		case <-interruptChannel:
+			log.Println("interrupt signal received")
+			cancel()
+		case <-ctx.Done():
+		}
+		<-interruptChannel
+		os.Exit(1)
+	}()
+
+	databaseConnection, err := config.CreateDatabaseConnection(ctx)
+	if err != nil {
+		log.Fatalf("error getting db connection: %v", err)
+	}
+	database := postgres.New(databaseConnection)
+
+	kafkaProducer, kafkaConfig, err := config.CreateKafkaProducer()
+	if err != nil {
+		log.Fatalf("error creating the kafka producer: %v", err)
+	}
+	if kafkaProducer != nil {
+		defer func() {
+			if err := kafkaProducer.Close(); err != nil {
+				log.Fatalf("error closing the producer: %v", err)
+			}
+		}()
+	}
+
+	keyring, err := config.CreateKeyring()
+	if err != nil {
+		log.Fatalf("error creating the encryption keyring: %v", err)
+	}
+	claimCheck, err := config.CreateClaimCheck(keyring)
+	if err != nil {
+		log.Fatalf("error creating the payload claim check: %v", err)
+	}
+
+	sagaService, err := config.CreateSagaService(
+		postgres.NewSagaRepository(*database, keyring, claimCheck),
+		postgres.NewTaskRepository(*database, keyring),
+		claimCheck,
+		config.SagaServiceOptions{Context: ctx, KafkaProducer: kafkaProducer, KafkaConfig: kafkaConfig},
+	)
+	if err != nil {
+		log.Fatalf("error creating the saga service: %v", err)
+	}
+
+	amqpURL := os.Getenv("SUKUNA_AMQP_URL")
+	if amqpURL == "" {
+		log.Fatal("SUKUNA_AMQP_URL is required")
+	}
+	retries, err := newRetryQueue(amqpURL)
+	if err != nil {
+		log.Fatalf("error creating the retry queue: %v", err)
+	}
+	defer retries.Close()
+
+	consumer, err := consume(amqpURL, Handler{ctx: ctx, SagaService: sagaService, Retries: retries})
+	if err != nil {
+		log.Fatalf("error consuming the results: %v", err)
+	}
+	defer func() {
+		consumer.StopConsuming(consumerName, false)
+		consumer.Disconnect()
+	}()
+
+	select {
+	case <-ctx.Done():
+	}
+}
+
+// consume reads the results from the `sukuna-out` queue, bound to the `sukuna` exchange. Messages are acknowledged
+// only after being handled, so a result is redelivered when the worker stops in the middle of it.
+func consume(amqpURL string, handler Handler) (rabbitmq.Consumer, error) {
+	consumer, err := rabbitmq.NewConsumer(amqpURL, amqp.Config{}, rabbitmq.WithConsumerOptionsLogging)
+	if err != nil {
+		return rabbitmq.Consumer{}, fmt.Errorf("error creating the consumer: %w", err)
+	}
+
+	err = consumer.StartConsuming(
+		handler.Handle,
+		step_execution.AMQPResultQueue,
+		[]string{step_execution.AMQPResultRoutingKey},
+		rabbitmq.WithConsumeOptionsQueueDurable,
+		rabbitmq.WithConsumeOptionsBindingExchangeName(step_execution.AMQPResultExchange),
+		rabbitmq.WithConsumeOptionsBindingExchangeKind(amqp.ExchangeDirect),
+		rabbitmq.WithConsumeOptionsBindingExchangeDurable,
+		rabbitmq.WithConsumeOptionsConsumerName(consumerName),
+		rabbitmq.WithConsumeOptionsConsumerAutoAck(false),
+	)
+	if err != nil {
+		return rabbitmq.Consumer{}, fmt.Errorf("error starting to consume: %w", err)
+	}
+
+	return consumer, nil
+}
+
+type Handler struct {
+	ctx context.Context
+
+	SagaService sagas.Service
+	Retries     *retryQueue
+}
+
+// Handle returns true to acknowledge the message. Results failing for a transient reason are acknowledged once
+// they're sent to the retry queue, to be handled again later, or return false to be requeued when they can't be sent.
+// Results that can't be read or can never be handled are acknowledged to not block the queue.
+func (h Handler) Handle(delivery rabbitmq.Delivery) bool {
+	vo, err := step_execution.UnmarshalStepResult(delivery.Body)
+	if err != nil {
+		log.Printf("error unmarshaling result: %v\n", err)
+		return true
+	}
+
+	log.Printf("result received: %v\n", vo)
+	if err := h.SagaService.HandleStepResult(h.ctx, vo); err != nil {
+		log.Printf("error handling the result: %v", err)
+		if sagas.IsPermanentResultError(err) {
+			return true
+		}
+		if err := h.Retries.Retry(delivery.Delivery); err != nil {
+			log.Printf("error retrying the result later, requeuing it: %v", err)
+			return false
+		}
+	}
+
+	return true
+}
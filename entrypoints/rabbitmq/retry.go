@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/thepabloaguilar/sukuna/gateways/step_execution"
+)
+
+const (
+	// resultRetryQueue has no consumers, its messages expire after resultRetryDelay into the result exchange
+	resultRetryQueue = "sukuna-out-retry"
+	// resultDeadLetterQueue keeps the results still failing after maxResultRetries
+	resultDeadLetterQueue = "sukuna-out-dlq"
+	resultRetriesHeader   = "sukuna-retries"
+
+	resultRetryDelay    = 10 * time.Second
+	maxResultRetries    = 10
+	retryConfirmTimeout = 10 * time.Second
+)
+
+var errRetryNotConfirmed = errors.New("result retry was not confirmed by the broker")
+
+// retryQueue delays the results failing for a transient reason, instead of requeuing them at the head of the result
+// queue to fail again right away. The channel is dropped whenever a publish fails, the next retry reconnects.
+type retryQueue struct {
+	mutex      sync.Mutex
+	url        string
+	connection *amqp.Connection
+	channel    *amqp.Channel
+	confirms   <-chan amqp.Confirmation
+	published  uint64
+}
+
+func newRetryQueue(url string) (*retryQueue, error) {
+	queue := &retryQueue{url: url}
+	if err := queue.connect(); err != nil {
+		return nil, err
+	}
+
+	return queue, nil
+}
+
+// Retry publishes the delivery to the retry queue, or to the dead-letter queue once it was retried maxResultRetries
+// times, counting the retries in the `sukuna-retries` header. The delivery can be acknowledged once it returns nil.
+func (q *retryQueue) Retry(delivery amqp.Delivery) error {
+	retries := deliveryRetries(delivery.Headers)
+	queue := resultRetryQueue
+	if retries >= maxResultRetries {
+		log.Printf("result failed after %d retries, sending it to %s", retries, resultDeadLetterQueue)
+		queue = resultDeadLetterQueue
+	}
+
+	headers := make(amqp.Table, len(delivery.Headers)+1)
+	for key, value := range delivery.Headers {
+		headers[key] = value
+	}
+	headers[resultRetriesHeader] = int32(retries + 1)
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if err := q.connect(); err != nil {
+		return err
+	}
+	if err := q.publish(queue, amqp.Publishing{
+		Headers:      headers,
+		ContentType:  delivery.ContentType,
+		DeliveryMode: amqp.Persistent,
+		Body:         delivery.Body,
+	}); err != nil {
+		q.reset()
+		return err
+	}
+
+	return nil
+}
+
+// publish sends the message through the default exchange, which routes it to the queue named as its routing key.
+func (q *retryQueue) publish(queue string, message amqp.Publishing) error {
+	if err := q.channel.Publish("", queue, false, false, message); err != nil {
+		return fmt.Errorf("error publishing to %s: %w", queue, err)
+	}
+	q.published++
+
+	timeout := time.After(retryConfirmTimeout)
+	for {
+		select {
+		case confirmation, ok := <-q.confirms:
+			if !ok {
+				return fmt.Errorf("%w: channel closed", errRetryNotConfirmed)
+			}
+			if confirmation.DeliveryTag < q.published {
+				continue
+			}
+			if !confirmation.Ack {
+				return errRetryNotConfirmed
+			}
+			return nil
+		case <-timeout:
+			return fmt.Errorf("%w: timeout waiting for the confirmation", errRetryNotConfirmed)
+		}
+	}
+}
+
+// connect opens the connection and the confirm channel, declaring the retry and the dead-letter queues, when they
+// aren't open yet.
+func (q *retryQueue) connect() error {
+	if q.channel != nil {
+		return nil
+	}
+
+	if q.connection == nil || q.connection.IsClosed() {
+		connection, err := amqp.Dial(q.url)
+		if err != nil {
+			return fmt.Errorf("error connecting to rabbitmq: %w", err)
+		}
+		q.connection = connection
+	}
+
+	channel, err := q.connection.Channel()
+	if err != nil {
+		_ = q.connection.Close()
+		q.connection = nil
+		return fmt.Errorf("error opening amqp channel: %w", err)
+	}
+	if err := declareRetryQueues(channel); err != nil {
+		_ = channel.Close()
+		return err
+	}
+	if err := channel.Confirm(false); err != nil {
+		_ = channel.Close()
+		return fmt.Errorf("error enabling publisher confirms: %w", err)
+	}
+
+	q.channel = channel
+	q.confirms = channel.NotifyPublish(make(chan amqp.Confirmation, 1))
+	q.published = 0
+	return nil
+}
+
+func (q *retryQueue) reset() {
+	if q.channel != nil {
+		_ = q.channel.Close()
+	}
+	q.channel = nil
+	q.confirms = nil
+}
+
+func (q *retryQueue) Close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.reset()
+	if q.connection != nil {
+		_ = q.connection.Close()
+	}
+}
+
+func declareRetryQueues(channel *amqp.Channel) error {
+	_, err := channel.QueueDeclare(resultRetryQueue, true, false, false, false, amqp.Table{
+		"x-message-ttl":             int32(resultRetryDelay / time.Millisecond),
+		"x-dead-letter-exchange":    step_execution.AMQPResultExchange,
+		"x-dead-letter-routing-key": step_execution.AMQPResultRoutingKey,
+	})
+	if err != nil {
+		return fmt.Errorf("error declaring queue %s: %w", resultRetryQueue, err)
+	}
+	if _, err := channel.QueueDeclare(resultDeadLetterQueue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("error declaring queue %s: %w", resultDeadLetterQueue, err)
+	}
+
+	return nil
+}
+
+// deliveryRetries reads the `sukuna-retries` header, zero when the result was never retried.
+func deliveryRetries(headers amqp.Table) int {
+	switch retries := headers[resultRetriesHeader].(type) {
+	case int32:
+		return int(retries)
+	case int64:
+		return int(retries)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/core/sagas/sagastest"
+	"github.com/thepabloaguilar/sukuna/gateways/step_execution"
+)
+
+// newTestClient connects to the Redis at `SUKUNA_REDIS_URL` and creates the consumer group of the results.
+func newTestClient(t *testing.T) *redis.Client {
+	redisURL := os.Getenv("SUKUNA_REDIS_URL")
+	if redisURL == "" {
+		t.Skip("SUKUNA_REDIS_URL isn't set")
+	}
+
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		t.Fatalf("error parsing redis url: %v", err)
+	}
+	client := redis.NewClient(options)
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Skipf("redis isn't available: %v", err)
+	}
+
+	err = client.XGroupCreateMkStream(context.Background(), step_execution.RedisResultStream, consumerGroup, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		t.Fatalf("error creating the consumer group: %v", err)
+	}
+
+	return client
+}
+
+// newTestConsumer names a consumer of the group, deleted along with its pending results when the test finishes.
+func newTestConsumer(t *testing.T, client *redis.Client) string {
+	consumer := fmt.Sprintf("sukuna-test-%s", uuid.NewString())
+	t.Cleanup(func() {
+		ctx := context.Background()
+		_ = client.XGroupDelConsumer(ctx, step_execution.RedisResultStream, consumerGroup, consumer).Err()
+	})
+
+	return consumer
+}
+
+// addAndRead adds the result to the stream and reads it through a consumer of its own, returned along with it.
+func addAndRead(t *testing.T, client *redis.Client, value string) (redis.XMessage, string) {
+	ctx := context.Background()
+	consumer := newTestConsumer(t, client)
+
+	err := client.XAdd(ctx, &redis.XAddArgs{
+		Stream: step_execution.RedisResultStream,
+		Values: map[string]interface{}{step_execution.RedisValueField: value},
+	}).Err()
+	if err != nil {
+		t.Fatalf("error adding the result: %v", err)
+	}
+
+	streams, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    consumerGroup,
+		Consumer: consumer,
+		Streams:  []string{step_execution.RedisResultStream, ">"},
+		Count:    1,
+		Block:    -1,
+	}).Result()
+	if err != nil {
+		t.Fatalf("error reading the result: %v", err)
+	}
+
+	return streams[0].Messages[0], consumer
+}
+
+func pendingCount(t *testing.T, client *redis.Client, consumer string) int {
+	pending, err := client.XPendingExt(context.Background(), &redis.XPendingExtArgs{
+		Stream:   step_execution.RedisResultStream,
+		Group:    consumerGroup,
+		Start:    "-",
+		End:      "+",
+		Count:    10,
+		Consumer: consumer,
+	}).Result()
+	if err != nil {
+		t.Fatalf("error reading the pending results: %v", err)
+	}
+
+	return len(pending)
+}
+
+func stepResult(t *testing.T) string {
+	data, err := json.Marshal(step_execution.StepResult{
+		StepIndex:   1,
+		ExecutionID: uuid.New(),
+		Result:      "success",
+		Output:      json.RawMessage(`{"booking_id":"42"}`),
+	})
+	if err != nil {
+		t.Fatalf("error marshaling the result: %v", err)
+	}
+
+	return string(data)
+}
+
+// handledTimes counts the results of the execution handled, claiming may handle the results left by other tests.
+func handledTimes(service *sagastest.ResultService, executionID uuid.UUID) int {
+	handled := 0
+	for _, result := range service.Results() {
+		if result.ExecutionID == executionID {
+			handled++
+		}
+	}
+
+	return handled
+}
+
+func newTestHandler(client *redis.Client, service *sagastest.ResultService) Handler {
+	return Handler{ctx: context.Background(), client: client, SagaService: service}
+}
+
+func TestHandleAcknowledgesHandledResults(t *testing.T) {
+	client := newTestClient(t)
+	service := sagastest.NewResultService(nil)
+
+	message, consumer := addAndRead(t, client, stepResult(t))
+	newTestHandler(client, service).Handle(message)
+
+	if results := service.Results(); len(results) != 1 || results[0].Result != "success" {
+		t.Fatalf("expected the result to be handled, got %v", results)
+	}
+	if pending := pendingCount(t, client, consumer); pending != 0 {
+		t.Fatalf("expected the result to be acknowledged, %d pending", pending)
+	}
+}
+
+func TestHandleLeavesTransientFailuresPending(t *testing.T) {
+	client := newTestClient(t)
+	service := sagastest.NewResultService(errors.New("database unavailable"))
+
+	message, consumer := addAndRead(t, client, stepResult(t))
+	newTestHandler(client, service).Handle(message)
+
+	if pending := pendingCount(t, client, consumer); pending != 1 {
+		t.Fatalf("expected the result to be left pending, %d pending", pending)
+	}
+}
+
+func TestClaimPendingRedeliversIdleResults(t *testing.T) {
+	client := newTestClient(t)
+	service := sagastest.NewResultService(errors.New("database unavailable"))
+	handler := newTestHandler(client, service)
+	message, deadConsumer := addAndRead(t, client, stepResult(t))
+	handler.Handle(message)
+
+	// Another replica claims the result left pending by the dead one
+	service.Fail(nil)
+	consumer := newTestConsumer(t, client)
+	claimPending(context.Background(), client, consumer, 0, handler)
+
+	if handled := handledTimes(service, service.Results()[0].ExecutionID); handled != 2 {
+		t.Fatalf("expected the result to be handled again, handled %d times", handled)
+	}
+	if pending := pendingCount(t, client, deadConsumer); pending != 0 {
+		t.Fatalf("expected the result to be claimed, %d pending", pending)
+	}
+	if pending := pendingCount(t, client, consumer); pending != 0 {
+		t.Fatalf("expected the claimed result to be acknowledged, %d pending", pending)
+	}
+}
+
+func TestClaimPendingLeavesRecentResults(t *testing.T) {
+	client := newTestClient(t)
+	service := sagastest.NewResultService(errors.New("database unavailable"))
+	handler := newTestHandler(client, service)
+	message, readingConsumer := addAndRead(t, client, stepResult(t))
+	handler.Handle(message)
+
+	claimPending(context.Background(), client, newTestConsumer(t, client), claimMinIdle, handler)
+
+	if handled := handledTimes(service, service.Results()[0].ExecutionID); handled != 1 {
+		t.Fatalf("expected the result not to be claimed, handled %d times", handled)
+	}
+	if pending := pendingCount(t, client, readingConsumer); pending != 1 {
+		t.Fatalf("expected the result to stay pending, %d pending", pending)
+	}
+}
+
+func TestHandleAcknowledgesPermanentFailures(t *testing.T) {
+	client := newTestClient(t)
+	service := sagastest.NewResultService(sagas.ErrSagaExecutionNotFound)
+
+	message, consumer := addAndRead(t, client, stepResult(t))
+	newTestHandler(client, service).Handle(message)
+
+	if pending := pendingCount(t, client, consumer); pending != 0 {
+		t.Fatalf("expected the result to be acknowledged, %d pending", pending)
+	}
+}
+
+func TestHandleAcknowledgesUnreadableResults(t *testing.T) {
+	client := newTestClient(t)
+	service := sagastest.NewResultService(nil)
+
+	message, consumer := addAndRead(t, client, "not json")
+	newTestHandler(client, service).Handle(message)
+
+	if results := service.Results(); len(results) != 0 {
+		t.Fatalf("expected the result not to be handled, got %v", results)
+	}
+	if pending := pendingCount(t, client, consumer); pending != 0 {
+		t.Fatalf("expected the result to be acknowledged, %d pending", pending)
+	}
+}
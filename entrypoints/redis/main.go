@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/thepabloaguilar/sukuna/config"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/gateways/postgres"
+	"github.com/thepabloaguilar/sukuna/gateways/step_execution"
+)
+
+const (
+	consumerGroup = "sukuna-worker"
+	readCount     = 10
+	readBlock     = 5 * time.Second
+	// Results pending for longer than claimMinIdle are taken from the consumer that read them, assumed dead
+	claimMinIdle  = time.Minute
+	claimInterval = 30 * time.Second
+)
+
+func main() {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	interruptChannel := make(chan os.Signal, 1)
+	signal.Notify(interruptChannel, os.Interrupt, os.Kill)
+	defer func() {
+		signal.Stop(interruptChannel)
+		cancel()
+	}()
+
+	go func() {
+		select {
+		case <-interruptChannel:
+			log.Println("interrupt signal received")
+			cancel()
+		case <-ctx.Done():
+		}
+		<-interruptChannel
+		os.Exit(1)
+	}()
+
+	databaseConnection, err := config.CreateDatabaseConnection(ctx)
+	if err != nil {
+		log.Fatalf("error getting db connection: %v", err)
+	}
+	database := postgres.New(databaseConnection)
+
+	kafkaProducer, kafkaConfig, err := config.CreateKafkaProducer()
+	if err != nil {
+		log.Fatalf("error creating the kafka producer: %v", err)
+	}
+	if kafkaProducer != nil {
+		defer func() {
+			if err := kafkaProducer.Close(); err != nil {
+				log.Fatalf("error closing the producer: %v", err)
+			}
+		}()
+	}
+
+	keyring, err := config.CreateKeyring()
+	if err != nil {
+		log.Fatalf("error creating the encryption keyring: %v", err)
+	}
+	claimCheck, err := config.CreateClaimCheck(keyring)
+	if err != nil {
+		log.Fatalf("error creating the payload claim check: %v", err)
+	}
+
+	redisClient, err := createRedisClient()
+	if err != nil {
+		log.Fatalf("error connecting to redis: %v", err)
+	}
+	defer func() {
+		if err := redisClient.Close(); err != nil {
+			log.Printf("error closing the redis client: %v", err)
+		}
+	}()
+
+	sagaService, err := config.CreateSagaService(
+		postgres.NewSagaRepository(*database, keyring, claimCheck),
+		postgres.NewTaskRepository(*database, keyring),
+		claimCheck,
+		config.SagaServiceOptions{
+			Context:       ctx,
+			KafkaProducer: kafkaProducer,
+			KafkaConfig:   kafkaConfig,
+			RedisClient:   redisClient,
+		},
+	)
+	if err != nil {
+		log.Fatalf("error creating the saga service: %v", err)
+	}
+
+	if err := consume(ctx, redisClient, sagaService); err != nil {
+		log.Fatalf("error consuming the results: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	}
+}
+
+// consume reads the results from the `sukuna-out` stream through the `sukuna-worker` consumer group, shared by every
+// worker replica. Results are acknowledged only after being handled and the ones left pending by a dead replica are
+// claimed, with XAUTOCLAIM, once they're idle for longer than claimMinIdle.
+func consume(ctx context.Context, client *redis.Client, sagaService sagas.Service) error {
+	err := client.XGroupCreateMkStream(ctx, step_execution.RedisResultStream, consumerGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("error creating the consumer group: %w", err)
+	}
+
+	consumer, err := consumerName()
+	if err != nil {
+		return err
+	}
+
+	handler := Handler{ctx: ctx, client: client, SagaService: sagaService}
+	go func() {
+		var lastClaim time.Time
+		for ctx.Err() == nil {
+			if time.Since(lastClaim) >= claimInterval {
+				claimPending(ctx, client, consumer, claimMinIdle, handler)
+				lastClaim = time.Now()
+			}
+
+			streams, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+				Group:    consumerGroup,
+				Consumer: consumer,
+				Streams:  []string{step_execution.RedisResultStream, ">"},
+				Count:    readCount,
+				Block:    readBlock,
+			}).Result()
+			if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+				continue
+			}
+			if err != nil {
+				log.Printf("error reading results: %v", err)
+				time.Sleep(readBlock)
+				continue
+			}
+
+			for _, stream := range streams {
+				for _, message := range stream.Messages {
+					handler.Handle(message)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// claimPending handles the results idle for longer than minIdle, going through the whole pending list.
+func claimPending(ctx context.Context, client *redis.Client, consumer string, minIdle time.Duration, handler Handler) {
+	start := "0-0"
+	for {
+		messages, next, err := autoClaim(ctx, client, consumer, minIdle, start)
+		if err != nil {
+			log.Printf("error claiming pending results: %v", err)
+			return
+		}
+
+		for _, message := range messages {
+			log.Printf("result %s claimed", message.ID)
+			handler.Handle(message)
+		}
+
+		if next == "0-0" {
+			return
+		}
+		start = next
+	}
+}
+
+// autoClaim sends XAUTOCLAIM by hand, go-redis only reads the Redis 6.2 reply while Redis 7 adds the deleted ids to it.
+func autoClaim(
+	ctx context.Context,
+	client *redis.Client,
+	consumer string,
+	minIdle time.Duration,
+	start string,
+) ([]redis.XMessage, string, error) {
+	reply, err := client.Do(
+		ctx,
+		"XAUTOCLAIM",
+		step_execution.RedisResultStream,
+		consumerGroup,
+		consumer,
+		minIdle.Milliseconds(),
+		start,
+		"COUNT",
+		readCount,
+	).Result()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return parseAutoClaimReply(reply)
+}
+
+// parseAutoClaimReply reads the next start id and the claimed entries, entries deleted from the stream are returned
+// without values.
+func parseAutoClaimReply(reply interface{}) ([]redis.XMessage, string, error) {
+	fields, _ := reply.([]interface{})
+	if len(fields) < 2 {
+		return nil, "", fmt.Errorf("unexpected XAUTOCLAIM reply: %v", reply)
+	}
+
+	next, _ := fields[0].(string)
+	entries, _ := fields[1].([]interface{})
+	messages := make([]redis.XMessage, 0, len(entries))
+	for _, entry := range entries {
+		entryFields, ok := entry.([]interface{})
+		if !ok || len(entryFields) != 2 {
+			continue
+		}
+
+		id, _ := entryFields[0].(string)
+		values, _ := entryFields[1].([]interface{})
+		message := redis.XMessage{ID: id, Values: make(map[string]interface{}, len(values)/2)}
+		for i := 0; i+1 < len(values); i += 2 {
+			key, _ := values[i].(string)
+			message.Values[key] = values[i+1]
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, next, nil
+}
+
+// consumerName identifies the replica in the consumer group, replicas running in the same host get different names.
+func consumerName() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("error getting the hostname: %w", err)
+	}
+
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid()), nil
+}
+
+// createRedisClient connects to the Redis at `SUKUNA_REDIS_URL`.
+func createRedisClient() (*redis.Client, error) {
+	redisURL := os.Getenv("SUKUNA_REDIS_URL")
+	if redisURL == "" {
+		return nil, errors.New("SUKUNA_REDIS_URL is required")
+	}
+
+	return config.CreateRedisClient(redisURL)
+}
+
+type Handler struct {
+	ctx    context.Context
+	client *redis.Client
+
+	SagaService sagas.Service
+}
+
+// Handle acknowledges the message once handled. Results failing for a transient reason are left pending to be
+// claimed again once idle, results that can't be read or can never be handled are acknowledged to not be claimed again.
+func (h Handler) Handle(message redis.XMessage) {
+	value, _ := message.Values[step_execution.RedisValueField].(string)
+	vo, err := step_execution.UnmarshalStepResult([]byte(value))
+	if err != nil {
+		log.Printf("error unmarshaling result: %v\n", err)
+		h.acknowledge(message)
+		return
+	}
+
+	log.Printf("result received: %v\n", vo)
+	if err := h.SagaService.HandleStepResult(h.ctx, vo); err != nil {
+		if !sagas.IsPermanentResultError(err) {
+			log.Printf("error handling the result, it will be claimed again: %v", err)
+			return
+		}
+		log.Printf("error handling the result: %v", err)
+	}
+
+	h.acknowledge(message)
+}
+
+func (h Handler) acknowledge(message redis.XMessage) {
+	err := h.client.XAck(h.ctx, step_execution.RedisResultStream, consumerGroup, message.ID).Err()
+	if err != nil {
+		log.Printf("error acknowledging the message: %v", err)
+	}
+}
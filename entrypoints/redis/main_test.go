@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseAutoClaimReply(t *testing.T) {
+	entry := []interface{}{"1-0", []interface{}{"value", `{"result":"success"}`}}
+	tests := []struct {
+		name     string
+		reply    interface{}
+		next     string
+		messages int
+		values   bool
+	}{
+		{name: "redis 6.2 reply", reply: []interface{}{"2-0", []interface{}{entry}}, next: "2-0", messages: 1, values: true},
+		{
+			name:     "redis 7 reply",
+			reply:    []interface{}{"0-0", []interface{}{entry}, []interface{}{"0-1"}},
+			next:     "0-0",
+			messages: 1,
+			values:   true,
+		},
+		{
+			name:     "deleted entry",
+			reply:    []interface{}{"0-0", []interface{}{[]interface{}{"1-0", nil}}},
+			next:     "0-0",
+			messages: 1,
+		},
+		{name: "nothing to claim", reply: []interface{}{"0-0", []interface{}{}}, next: "0-0"},
+		{name: "invalid entry", reply: []interface{}{"0-0", []interface{}{"1-0"}}, next: "0-0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			messages, next, err := parseAutoClaimReply(test.reply)
+			if err != nil {
+				t.Fatalf("error parsing the reply: %v", err)
+			}
+
+			if next != test.next {
+				t.Fatalf("expected the next start %s, got %s", test.next, next)
+			}
+			if len(messages) != test.messages {
+				t.Fatalf("expected %d messages, got %v", test.messages, messages)
+			}
+			if test.values && messages[0].Values["value"] != `{"result":"success"}` {
+				t.Fatalf("expected the message values to be read, got %v", messages[0].Values)
+			}
+			if !test.values && len(messages) > 0 && len(messages[0].Values) > 0 {
+				t.Fatalf("expected the message to have no values, got %v", messages[0].Values)
+			}
+		})
+	}
+}
+
+func TestParseAutoClaimReplyRefusesUnexpectedReplies(t *testing.T) {
+	for _, reply := range []interface{}{nil, "OK", []interface{}{"0-0"}} {
+		if _, _, err := parseAutoClaimReply(reply); err == nil {
+			t.Fatalf("expected the reply %v to be refused", reply)
+		}
+	}
+}
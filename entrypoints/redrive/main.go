@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/Shopify/sarama"
+	"github.com/thepabloaguilar/sukuna/config"
+	"github.com/thepabloaguilar/sukuna/gateways/step_execution"
+)
+
+// redriveGroupName keeps the offsets of the re-driven messages, so each run only sends the new dead letters
+const redriveGroupName = "sukuna-redrive"
+
+// The redrive command sends the messages of the dead-letter topic back to the topics they failed at, once the cause of
+// their failure is fixed. It stops at the last message present when it starts, so messages failing again aren't re-driven
+// in a loop.
+func main() {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	interruptChannel := make(chan os.Signal, 1)
+	signal.Notify(interruptChannel, os.Interrupt, os.Kill)
+	defer func() {
+		signal.Stop(interruptChannel)
+		cancel()
+	}()
+
+	go func() {
+		select {
+		case <-interruptChannel:
+			log.Println("interrupt signal received")
+			cancel()
+		case <-ctx.Done():
+		}
+		<-interruptChannel
+		os.Exit(1)
+	}()
+
+	limit := flag.Int("limit", 0, "maximum number of messages re-driven, all of them when 0")
+	flag.Parse()
+
+	naming := step_execution.TopicNaming{
+		Prefix:      os.Getenv("SUKUNA_KAFKA_TOPIC_PREFIX"),
+		Environment: os.Getenv("SUKUNA_KAFKA_TOPIC_ENVIRONMENT"),
+	}
+
+	kafkaBrokers := config.KafkaBrokers()
+	if kafkaBrokers == nil {
+		log.Fatalf("SUKUNA_KAFKA_BROKERS is required")
+	}
+
+	clientConfig := sarama.NewConfig()
+	clientConfig.Producer.Return.Successes = true
+	clientConfig.Consumer.Offsets.Initial = sarama.OffsetOldest
+	client, err := sarama.NewClient(kafkaBrokers, clientConfig)
+	if err != nil {
+		log.Fatalf("error creating the kafka client: %v", err)
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			log.Printf("error closing the kafka client: %v", err)
+		}
+	}()
+
+	redriven, err := redrive(ctx, client, naming, *limit)
+	if err != nil {
+		log.Printf("error re-driving the dead letters: %v", err)
+	}
+	log.Printf("%d messages re-driven", redriven)
+}
+
+// redrive sends the dead letters back partition by partition, committing the offset of each message once it's sent.
+func redrive(ctx context.Context, client sarama.Client, naming step_execution.TopicNaming, limit int) (int, error) {
+	topic := naming.DeadLetterTopic()
+	partitions, err := client.Partitions(topic)
+	if err != nil {
+		return 0, fmt.Errorf("error getting the partitions of %s: %w", topic, err)
+	}
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		return 0, fmt.Errorf("error creating the producer: %w", err)
+	}
+	defer producer.Close()
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return 0, fmt.Errorf("error creating the consumer: %w", err)
+	}
+	defer consumer.Close()
+	offsetManager, err := sarama.NewOffsetManagerFromClient(redriveGroupName, client)
+	if err != nil {
+		return 0, fmt.Errorf("error creating the offset manager: %w", err)
+	}
+	// Closing the offset manager commits the marked offsets
+	defer offsetManager.Close()
+
+	redriven := 0
+	for _, partition := range partitions {
+		if limit > 0 && redriven >= limit {
+			break
+		}
+
+		sent, err := redrivePartition(
+			ctx, client, producer, consumer, offsetManager, naming, topic, partition, limit-redriven,
+		)
+		redriven += sent
+		if err != nil {
+			return redriven, err
+		}
+	}
+
+	return redriven, nil
+}
+
+func redrivePartition(
+	ctx context.Context,
+	client sarama.Client,
+	producer sarama.SyncProducer,
+	consumer sarama.Consumer,
+	offsetManager sarama.OffsetManager,
+	naming step_execution.TopicNaming,
+	topic string,
+	partition int32,
+	limit int,
+) (int, error) {
+	partitionOffsets, err := offsetManager.ManagePartition(topic, partition)
+	if err != nil {
+		return 0, fmt.Errorf("error managing the offsets of partition %d: %w", partition, err)
+	}
+	defer partitionOffsets.AsyncClose()
+
+	// The newest offset is the one of the next message, it's where the dead letters of this run end
+	endOffset, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, fmt.Errorf("error getting the newest offset of partition %d: %w", partition, err)
+	}
+	offset, _ := partitionOffsets.NextOffset()
+	if offset == sarama.OffsetOldest {
+		if offset, err = client.GetOffset(topic, partition, sarama.OffsetOldest); err != nil {
+			return 0, fmt.Errorf("error getting the oldest offset of partition %d: %w", partition, err)
+		}
+	}
+	if offset >= endOffset {
+		return 0, nil
+	}
+
+	partitionConsumer, err := consumer.ConsumePartition(topic, partition, offset)
+	if err != nil {
+		return 0, fmt.Errorf("error consuming partition %d: %w", partition, err)
+	}
+	defer partitionConsumer.AsyncClose()
+
+	redriven := 0
+	for limit <= 0 || redriven < limit {
+		var message *sarama.ConsumerMessage
+		select {
+		case <-ctx.Done():
+			return redriven, ctx.Err()
+		case message = <-partitionConsumer.Messages():
+		}
+
+		if _, _, err := producer.SendMessage(step_execution.RedriveMessage(message, naming.ResultTopic())); err != nil {
+			return redriven, fmt.Errorf("error re-driving the message at offset %d: %w", message.Offset, err)
+		}
+		partitionOffsets.MarkOffset(message.Offset+1, "")
+		redriven++
+
+		if message.Offset+1 >= endOffset {
+			break
+		}
+	}
+
+	return redriven, nil
+}
@@ -0,0 +1,291 @@
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/retention"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/gateways/encryption"
+)
+
+var ErrInvalidLocation = errors.New("invalid archive location")
+
+type archivedExecution struct {
+	SagaExecutionID uuid.UUID               `json:"saga_execution_id"`
+	SagaID          uuid.UUID               `json:"saga_id"`
+	Payload         json.RawMessage         `json:"payload"`
+	Status          string                  `json:"status"`
+	CreatedAt       time.Time               `json:"created_at"`
+	FinishedAt      *time.Time              `json:"finished_at,omitempty"`
+	SensitiveFields []string                `json:"sensitive_fields"`
+	Steps           []archivedExecutionStep `json:"steps"`
+}
+
+type archivedExecutionStep struct {
+	Index        int             `json:"index"`
+	Name         string          `json:"name"`
+	Status       string          `json:"status"`
+	InputMapping json.RawMessage `json:"input_mapping,omitempty"`
+	Output       json.RawMessage `json:"output,omitempty"`
+	StartedAt    *time.Time      `json:"started_at,omitempty"`
+	FinishedAt   *time.Time      `json:"finished_at,omitempty"`
+	Failed       bool            `json:"failed,omitempty"`
+	// Error holds an encrypted archivedError, or the plain one in archives written before errors were encrypted
+	Error json.RawMessage `json:"error,omitempty"`
+}
+
+type archivedError struct {
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	Retryable bool            `json:"retryable"`
+	Details   json.RawMessage `json:"details,omitempty"`
+}
+
+type fileArchive struct {
+	directory string
+	keyring   encryption.Keyring
+}
+
+// NewFileArchive writes the executions as gzip compressed NDJSON files, one file per archived batch.
+// Sensitive fields are encrypted with the keyring just like they're in the database, steps output and errors, which
+// have no schema, are encrypted as a whole.
+func NewFileArchive(directory string, keyring encryption.Keyring) (retention.ExecutionArchive, error) {
+	absoluteDirectory, err := filepath.Abs(directory)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving archive directory: %w", err)
+	}
+
+	return fileArchive{directory: absoluteDirectory, keyring: keyring}, nil
+}
+
+// Write names the file after the first execution of the batch, a batch written again because it failed to be deleted
+// from the database overwrites the previous file instead of leaving a copy of its executions behind.
+func (a fileArchive) Write(
+	_ context.Context,
+	sagaID uuid.UUID,
+	executions []sagas.SagaExecutionVO,
+) (string, error) {
+	if len(executions) == 0 {
+		return "", errors.New("no executions to archive")
+	}
+
+	location := filepath.ToSlash(filepath.Join(
+		sagaID.String(),
+		fmt.Sprintf("%s.ndjson.gz", executions[0].SagaExecutionID),
+	))
+	path := filepath.Join(a.directory, filepath.FromSlash(location))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", fmt.Errorf("error creating archive directory: %w", err)
+	}
+
+	// The file is only replaced once it's completely written, a failure halfway keeps the previous one
+	file, err := ioutil.TempFile(filepath.Dir(path), ".archive-*")
+	if err != nil {
+		return "", fmt.Errorf("error creating archive file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	writer := gzip.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, execution := range executions {
+		archived, err := a.toArchivedExecution(execution)
+		if err != nil {
+			return "", err
+		}
+		if err := encoder.Encode(archived); err != nil {
+			return "", fmt.Errorf("error writing archived execution: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("error compressing archive file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return "", fmt.Errorf("error syncing archive file: %w", err)
+	}
+	if err := file.Chmod(0o640); err != nil {
+		return "", fmt.Errorf("error setting archive file permissions: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("error closing archive file: %w", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return "", fmt.Errorf("error moving archive file: %w", err)
+	}
+
+	return location, nil
+}
+
+func (a fileArchive) toArchivedExecution(execution sagas.SagaExecutionVO) (archivedExecution, error) {
+	payload, err := sagas.TransformFields(execution.Payload, execution.SensitiveFields, a.keyring.Encrypt)
+	if err != nil {
+		return archivedExecution{}, fmt.Errorf("error encrypting sensitive fields: %w", err)
+	}
+
+	archived := archivedExecution{
+		SagaExecutionID: execution.SagaExecutionID,
+		SagaID:          execution.SagaID,
+		Payload:         payload,
+		Status:          string(execution.Status),
+		CreatedAt:       execution.CreatedAt,
+		FinishedAt:      execution.FinishedAt,
+		SensitiveFields: execution.SensitiveFields,
+		Steps:           make([]archivedExecutionStep, 0, len(execution.Steps)),
+	}
+	for _, step := range execution.Steps {
+		output, err := a.keyring.EncryptDocument(step.Output)
+		if err != nil {
+			return archivedExecution{}, fmt.Errorf("error encrypting output of step %s: %w", step.Name, err)
+		}
+
+		var stepError json.RawMessage
+		if step.Error != nil {
+			record, err := json.Marshal(archivedError{
+				Code:      step.Error.Code,
+				Message:   step.Error.Message,
+				Retryable: step.Error.Retryable,
+				Details:   step.Error.Details,
+			})
+			if err != nil {
+				return archivedExecution{}, fmt.Errorf("error marshaling error of step %s: %w", step.Name, err)
+			}
+			if stepError, err = a.keyring.EncryptDocument(record); err != nil {
+				return archivedExecution{}, fmt.Errorf("error encrypting error of step %s: %w", step.Name, err)
+			}
+		}
+
+		archived.Steps = append(archived.Steps, archivedExecutionStep{
+			Index:        step.Index,
+			Name:         step.Name,
+			Status:       string(step.Status),
+			InputMapping: step.InputMapping,
+			Output:       output,
+			StartedAt:    step.StartedAt,
+			FinishedAt:   step.FinishedAt,
+			Failed:       step.Failed,
+			Error:        stepError,
+		})
+	}
+
+	return archived, nil
+}
+
+func (a fileArchive) Read(
+	_ context.Context,
+	location string,
+	executionID uuid.UUID,
+) (sagas.SagaExecutionVO, error) {
+	path := filepath.Join(a.directory, filepath.FromSlash(location))
+	if !strings.HasPrefix(path, a.directory+string(filepath.Separator)) {
+		return sagas.SagaExecutionVO{}, ErrInvalidLocation
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return sagas.SagaExecutionVO{}, fmt.Errorf("error opening archive file: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return sagas.SagaExecutionVO{}, fmt.Errorf("error decompressing archive file: %w", err)
+	}
+	defer reader.Close()
+
+	lines := bufio.NewReader(reader)
+	for {
+		line, err := lines.ReadBytes('\n')
+		if len(line) > 0 {
+			var archived archivedExecution
+			if err := json.Unmarshal(line, &archived); err != nil {
+				return sagas.SagaExecutionVO{}, fmt.Errorf("error reading archived execution: %w", err)
+			}
+			if archived.SagaExecutionID == executionID {
+				return a.toSagaExecutionVO(archived)
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return sagas.SagaExecutionVO{}, retention.ErrArchivedExecutionNotFound
+		}
+		if err != nil {
+			return sagas.SagaExecutionVO{}, fmt.Errorf("error reading archive file: %w", err)
+		}
+	}
+}
+
+func (a fileArchive) toSagaExecutionVO(archived archivedExecution) (sagas.SagaExecutionVO, error) {
+	payload, err := sagas.TransformFields(archived.Payload, archived.SensitiveFields, a.keyring.Decrypt)
+	if err != nil {
+		return sagas.SagaExecutionVO{}, fmt.Errorf("error decrypting sensitive fields: %w", err)
+	}
+
+	vo := sagas.SagaExecutionVO{
+		SagaExecution: entities.SagaExecution{
+			SagaExecutionID: archived.SagaExecutionID,
+			SagaID:          archived.SagaID,
+			Payload:         payload,
+			Status:          entities.SagaExecutionStatus(archived.Status),
+			CreatedAt:       archived.CreatedAt,
+			FinishedAt:      archived.FinishedAt,
+		},
+		Steps:           make([]entities.StepExecution, 0, len(archived.Steps)),
+		SensitiveFields: archived.SensitiveFields,
+	}
+	for _, step := range archived.Steps {
+		output, err := a.keyring.DecryptDocument(step.Output)
+		if err != nil {
+			return sagas.SagaExecutionVO{}, fmt.Errorf("error decrypting output of step %s: %w", step.Name, err)
+		}
+
+		var stepError *entities.StepError
+		if len(step.Error) > 0 && string(step.Error) != "null" {
+			record, err := a.keyring.DecryptDocument(step.Error)
+			if err != nil {
+				return sagas.SagaExecutionVO{}, fmt.Errorf("error decrypting error of step %s: %w", step.Name, err)
+			}
+
+			var archivedStepError archivedError
+			if err := json.Unmarshal(record, &archivedStepError); err != nil {
+				return sagas.SagaExecutionVO{}, fmt.Errorf("error reading error of step %s: %w", step.Name, err)
+			}
+			stepError = &entities.StepError{
+				Code:      archivedStepError.Code,
+				Message:   archivedStepError.Message,
+				Retryable: archivedStepError.Retryable,
+				Details:   archivedStepError.Details,
+			}
+		}
+
+		vo.Steps = append(vo.Steps, entities.StepExecution{
+			SagaExecutionID: archived.SagaExecutionID,
+			Index:           step.Index,
+			Name:            step.Name,
+			Status:          entities.StepExecutionStatus(step.Status),
+			InputMapping:    step.InputMapping,
+			Output:          output,
+			StartedAt:       step.StartedAt,
+			FinishedAt:      step.FinishedAt,
+			Error:           stepError,
+			// Archives written before the failed flag only had the status and the error to tell it
+			Failed: step.Failed || step.Status == string(entities.StepExecutionError) || stepError != nil,
+		})
+	}
+
+	return vo, nil
+}
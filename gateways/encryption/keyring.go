@@ -0,0 +1,228 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const encryptedValuePrefix = "sukuna:enc:v1:"
+
+var ErrMissingKey = errors.New("encryption key not found")
+var ErrInvalidKey = errors.New("encryption keys must have 32 bytes")
+var ErrInvalidEncryptedValue = errors.New("invalid encrypted value")
+
+// Keyring encrypts values with the current key and decrypts values encrypted by any known key.
+//
+// Rotating a key means adding a new key, making it the current one and keeping the old one until every value
+// encrypted with it was rewritten.
+type Keyring struct {
+	currentKeyID string
+	keys         map[string]cipher.AEAD
+}
+
+func NewKeyring(currentKeyID string, keys map[string][]byte) (Keyring, error) {
+	keyring := Keyring{
+		currentKeyID: currentKeyID,
+		keys:         make(map[string]cipher.AEAD, len(keys)),
+	}
+
+	for keyID, key := range keys {
+		if len(key) != 32 {
+			return Keyring{}, fmt.Errorf("%w: %s", ErrInvalidKey, keyID)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return Keyring{}, fmt.Errorf("error creating cipher for key %s: %w", keyID, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return Keyring{}, fmt.Errorf("error creating cipher for key %s: %w", keyID, err)
+		}
+		keyring.keys[keyID] = aead
+	}
+
+	if currentKeyID != "" {
+		if _, ok := keyring.keys[currentKeyID]; !ok {
+			return Keyring{}, fmt.Errorf("%w: %s", ErrMissingKey, currentKeyID)
+		}
+	}
+
+	return keyring, nil
+}
+
+// ParseKeys parses keys in the `key-id:base64-key,other-key-id:base64-key` format.
+func ParseKeys(value string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid key definition %q", item)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("error decoding key %s: %w", parts[0], err)
+		}
+		keys[parts[0]] = key
+	}
+
+	return keys, nil
+}
+
+// CanEncrypt tells whether there's a current key to encrypt new values with.
+func (k Keyring) CanEncrypt() bool {
+	_, ok := k.keys[k.currentKeyID]
+	return ok
+}
+
+// Encrypt encrypts the JSON value with the current key, the result is a string holding the key id. Values are always
+// encrypted, even the ones looking encrypted already, so decrypting gives back exactly what was encrypted.
+func (k Keyring) Encrypt(value interface{}) (interface{}, error) {
+	aead, ok := k.keys[k.currentKeyID]
+	if !ok {
+		return nil, ErrMissingKey
+	}
+
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling value: %w", err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(k.currentKeyID))
+
+	return encryptedValuePrefix + k.currentKeyID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the original JSON value of an encrypted value, any other value is returned as is.
+func (k Keyring) Decrypt(value interface{}) (interface{}, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	plaintext, err := k.open(value.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	var decrypted interface{}
+	if err := json.Unmarshal(plaintext, &decrypted); err != nil {
+		return nil, fmt.Errorf("error unmarshaling decrypted value: %w", err)
+	}
+
+	return decrypted, nil
+}
+
+func (k Keyring) open(value string) ([]byte, error) {
+	parts := strings.SplitN(strings.TrimPrefix(value, encryptedValuePrefix), ":", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidEncryptedValue
+	}
+	keyID, encoded := parts[0], parts[1]
+
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMissingKey, keyID)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidEncryptedValue
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting value: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// EncryptDocument encrypts the whole JSON document into a single encrypted value, for documents without a schema
+// telling which fields are sensitive, e.g. the steps output. Without a current key the document is kept as is.
+func (k Keyring) EncryptDocument(document []byte) ([]byte, error) {
+	if len(document) == 0 || k.currentKeyID == "" {
+		return document, nil
+	}
+
+	encrypted, err := k.Encrypt(json.RawMessage(document))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(encrypted)
+}
+
+// DecryptDocument returns the document encrypted as a whole by EncryptDocument, documents written without a current
+// key are returned as is. The values of a document are never decrypted, only the sensitive fields of a payload are,
+// see `sagas.TransformFields`.
+func (k Keyring) DecryptDocument(document []byte) ([]byte, error) {
+	if len(document) == 0 || !strings.HasPrefix(string(document), `"`+encryptedValuePrefix) {
+		return document, nil
+	}
+
+	var value string
+	if err := json.Unmarshal(document, &value); err != nil {
+		return nil, fmt.Errorf("error unmarshaling document: %w", err)
+	}
+
+	return k.open(value)
+}
+
+// HasEncryptedValues tells whether any string of the JSON document has the format of the encrypted values, documents
+// sent by clients can't have them since they'd be taken for encrypted ones.
+func HasEncryptedValues(document []byte) (bool, error) {
+	if !strings.Contains(string(document), encryptedValuePrefix) {
+		return false, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(document, &value); err != nil {
+		return false, fmt.Errorf("error unmarshaling document: %w", err)
+	}
+
+	return hasEncryptedValue(value), nil
+}
+
+func hasEncryptedValue(value interface{}) bool {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for _, item := range typed {
+			if hasEncryptedValue(item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range typed {
+			if hasEncryptedValue(item) {
+				return true
+			}
+		}
+	default:
+		return IsEncrypted(value)
+	}
+
+	return false
+}
+
+func IsEncrypted(value interface{}) bool {
+	str, ok := value.(string)
+	return ok && strings.HasPrefix(str, encryptedValuePrefix)
+}
@@ -0,0 +1,97 @@
+package encryption
+
+import (
+	"bytes"
+	"testing"
+)
+
+func newTestKeyring(t *testing.T) Keyring {
+	keyring, err := NewKeyring("2021-10", map[string][]byte{"2021-10": bytes.Repeat([]byte("k"), 32)})
+	if err != nil {
+		t.Fatalf("error creating the keyring: %v", err)
+	}
+
+	return keyring
+}
+
+func TestEncryptValuesLookingEncrypted(t *testing.T) {
+	keyring := newTestKeyring(t)
+	value := encryptedValuePrefix + "garbage"
+
+	encrypted, err := keyring.Encrypt(value)
+	if err != nil {
+		t.Fatalf("error encrypting: %v", err)
+	}
+	if encrypted == value {
+		t.Fatal("expected the value to be encrypted")
+	}
+
+	decrypted, err := keyring.Decrypt(encrypted)
+	if err != nil {
+		t.Fatalf("error decrypting: %v", err)
+	}
+	if decrypted != value {
+		t.Fatalf("expected %q, got %q", value, decrypted)
+	}
+}
+
+func TestDecryptDocument(t *testing.T) {
+	keyring := newTestKeyring(t)
+	tests := []string{
+		`{"receipt":"42"}`,
+		`"` + encryptedValuePrefix + `garbage"`,
+	}
+
+	for _, document := range tests {
+		t.Run(document, func(t *testing.T) {
+			encrypted, err := keyring.EncryptDocument([]byte(document))
+			if err != nil {
+				t.Fatalf("error encrypting: %v", err)
+			}
+
+			decrypted, err := keyring.DecryptDocument(encrypted)
+			if err != nil {
+				t.Fatalf("error decrypting: %v", err)
+			}
+			if string(decrypted) != document {
+				t.Fatalf("expected %s, got %s", document, decrypted)
+			}
+		})
+	}
+}
+
+func TestDecryptDocumentKeepsItsValues(t *testing.T) {
+	document := []byte(`{"receipt": "` + encryptedValuePrefix + `garbage"}`)
+
+	decrypted, err := newTestKeyring(t).DecryptDocument(document)
+	if err != nil {
+		t.Fatalf("error decrypting: %v", err)
+	}
+	if !bytes.Equal(decrypted, document) {
+		t.Fatalf("expected %s, got %s", document, decrypted)
+	}
+}
+
+func TestHasEncryptedValues(t *testing.T) {
+	tests := []struct {
+		document string
+		found    bool
+	}{
+		{document: `{"card": "4111"}`},
+		{document: `{"card": "` + encryptedValuePrefix + `garbage"}`, found: true},
+		{document: `{"cards": [{"number": "` + encryptedValuePrefix + `garbage"}]}`, found: true},
+		{document: `{"note": "see ` + encryptedValuePrefix + `"}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.document, func(t *testing.T) {
+			found, err := HasEncryptedValues([]byte(test.document))
+			if err != nil {
+				t.Fatalf("error reading the document: %v", err)
+			}
+			if found != test.found {
+				t.Fatalf("expected %v, got %v", test.found, found)
+			}
+		})
+	}
+}
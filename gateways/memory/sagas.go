@@ -0,0 +1,454 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+)
+
+// SagaRepository keeps the sagas and their executions in memory, for tests and services running sukuna in-process.
+// It behaves like the postgres repository, entities are copied in and out so callers can't change what's stored.
+type SagaRepository struct {
+	mutex          *sync.RWMutex
+	sagas          map[uuid.UUID]entities.Saga
+	steps          map[uuid.UUID][]entities.SagaStep
+	executions     map[uuid.UUID]entities.SagaExecution
+	stepExecutions map[uuid.UUID][]entities.StepExecution
+}
+
+func NewSagaRepository() SagaRepository {
+	return SagaRepository{
+		mutex:          &sync.RWMutex{},
+		sagas:          make(map[uuid.UUID]entities.Saga),
+		steps:          make(map[uuid.UUID][]entities.SagaStep),
+		executions:     make(map[uuid.UUID]entities.SagaExecution),
+		stepExecutions: make(map[uuid.UUID][]entities.StepExecution),
+	}
+}
+
+func (r SagaRepository) GetSaga(_ context.Context, sagaID uuid.UUID) (entities.Saga, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	saga, ok := r.sagas[sagaID]
+	if !ok {
+		return entities.Saga{}, sagas.ErrSagaNotFound
+	}
+
+	return saga, nil
+}
+
+// ListSagas returns the sagas without their payloads
+func (r SagaRepository) ListSagas(_ context.Context, filter sagas.SagaFilter) ([]entities.Saga, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	found := make([]entities.Saga, 0)
+	for _, saga := range r.sagas {
+		if filter.Name != "" && !strings.Contains(strings.ToLower(saga.Name), strings.ToLower(filter.Name)) {
+			continue
+		}
+		if filter.FormattedName != "" && saga.FormattedName != filter.FormattedName {
+			continue
+		}
+		if !inTimeWindow(saga.CreatedAt, filter.CreatedAfter, filter.CreatedBefore) {
+			continue
+		}
+		if filter.Deprecated != nil && saga.IsDeprecated() != *filter.Deprecated {
+			continue
+		}
+		if filter.After != nil && !isBeforeCursor(saga.CreatedAt, saga.SagaID, *filter.After) {
+			continue
+		}
+
+		saga.Payload = nil
+		found = append(found, saga)
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return isBeforeCursor(
+			found[j].CreatedAt,
+			found[j].SagaID,
+			sagas.Cursor{CreatedAt: found[i].CreatedAt, ID: found[i].SagaID},
+		)
+	})
+
+	if filter.Limit > 0 && len(found) > filter.Limit {
+		found = found[:filter.Limit]
+	}
+
+	return found, nil
+}
+
+func (r SagaRepository) CreateSaga(_ context.Context, saga entities.Saga) (entities.Saga, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	saga.SagaID = uuid.New()
+	saga.CreatedAt = now()
+	r.sagas[saga.SagaID] = saga
+
+	return saga, nil
+}
+
+func (r SagaRepository) UpdateSaga(_ context.Context, saga entities.Saga) (entities.Saga, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, ok := r.sagas[saga.SagaID]
+	if !ok {
+		return entities.Saga{}, sagas.ErrSagaNotFound
+	}
+
+	stored.Name = saga.Name
+	stored.Description = saga.Description
+	stored.RetentionDays = saga.RetentionDays
+	r.sagas[saga.SagaID] = stored
+
+	return stored, nil
+}
+
+func (r SagaRepository) DeprecateSaga(_ context.Context, sagaID uuid.UUID) (entities.Saga, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	saga, ok := r.sagas[sagaID]
+	if !ok {
+		return entities.Saga{}, sagas.ErrSagaNotFound
+	}
+
+	if saga.DeprecatedAt == nil {
+		deprecatedAt := now()
+		saga.DeprecatedAt = &deprecatedAt
+		r.sagas[sagaID] = saga
+	}
+
+	return saga, nil
+}
+
+// DeleteSaga deletes the saga and its steps only when it has no executions.
+func (r SagaRepository) DeleteSaga(_ context.Context, sagaID uuid.UUID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, execution := range r.executions {
+		if execution.SagaID == sagaID {
+			return sagas.ErrSagaHasExecutions
+		}
+	}
+
+	delete(r.sagas, sagaID)
+	delete(r.steps, sagaID)
+	return nil
+}
+
+func (r SagaRepository) GetSagaStepsBySagaID(_ context.Context, sagaID uuid.UUID) ([]entities.SagaStep, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return append([]entities.SagaStep{}, r.steps[sagaID]...), nil
+}
+
+func (r SagaRepository) GetSagaStepsBySagaIDs(_ context.Context, sagaIDs []uuid.UUID) ([]entities.SagaStep, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	steps := make([]entities.SagaStep, 0)
+	for _, sagaID := range sagaIDs {
+		steps = append(steps, r.steps[sagaID]...)
+	}
+
+	return steps, nil
+}
+
+func (r SagaRepository) CreateSagaSteps(_ context.Context, steps []entities.SagaStep) ([]entities.SagaStep, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	savedSteps := make([]entities.SagaStep, 0, len(steps))
+	for _, step := range steps {
+		step.StepID = uuid.New()
+		r.steps[step.SagaID] = append(r.steps[step.SagaID], step)
+		savedSteps = append(savedSteps, step)
+	}
+	for sagaID := range r.steps {
+		sortSagaSteps(r.steps[sagaID])
+	}
+
+	return savedSteps, nil
+}
+
+func (r SagaRepository) GetSagaExecution(_ context.Context, executionID uuid.UUID) (entities.SagaExecution, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	execution, ok := r.executions[executionID]
+	if !ok {
+		return entities.SagaExecution{}, sagas.ErrSagaExecutionNotFound
+	}
+
+	return execution, nil
+}
+
+func (r SagaRepository) CreateSagaExecution(
+	_ context.Context,
+	execution entities.SagaExecution,
+) (entities.SagaExecution, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	execution.SagaExecutionID = uuid.New()
+	execution.Payload = append([]byte{}, execution.Payload...)
+	execution.CreatedAt = now()
+	r.executions[execution.SagaExecutionID] = execution
+
+	return execution, nil
+}
+
+// ListSagaExecutions returns the executions without their payloads
+func (r SagaRepository) ListSagaExecutions(
+	_ context.Context,
+	filter sagas.SagaExecutionFilter,
+) ([]entities.SagaExecution, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	found := make([]entities.SagaExecution, 0)
+	for _, execution := range r.executions {
+		if filter.SagaID != nil && execution.SagaID != *filter.SagaID {
+			continue
+		}
+		if filter.Status != "" && execution.Status != filter.Status {
+			continue
+		}
+		if filter.StepStatus != "" && !r.hasStepWithStatus(execution.SagaExecutionID, filter.StepStatus) {
+			continue
+		}
+		if !inTimeWindow(execution.CreatedAt, filter.CreatedAfter, filter.CreatedBefore) {
+			continue
+		}
+		if filter.After != nil && !isBeforeCursor(execution.CreatedAt, execution.SagaExecutionID, *filter.After) {
+			continue
+		}
+
+		execution.Payload = nil
+		found = append(found, execution)
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return isBeforeCursor(
+			found[j].CreatedAt,
+			found[j].SagaExecutionID,
+			sagas.Cursor{CreatedAt: found[i].CreatedAt, ID: found[i].SagaExecutionID},
+		)
+	})
+
+	if filter.Limit > 0 && len(found) > filter.Limit {
+		found = found[:filter.Limit]
+	}
+
+	return found, nil
+}
+
+func (r SagaRepository) hasStepWithStatus(executionID uuid.UUID, status entities.StepExecutionStatus) bool {
+	for _, step := range r.stepExecutions[executionID] {
+		// Failed steps are found even after they compensated themselves
+		if step.Status == status || (status == entities.StepExecutionError && step.Failed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r SagaRepository) SetSagaExecutionStatus(
+	_ context.Context,
+	status entities.SagaExecutionStatus,
+	executionID uuid.UUID,
+) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	execution, ok := r.executions[executionID]
+	if !ok {
+		return nil
+	}
+
+	execution.Status = status
+	if status == entities.SagaExecutionSucceeded || status == entities.SagaExecutionFailed {
+		finishedAt := now()
+		execution.FinishedAt = &finishedAt
+	}
+	r.executions[executionID] = execution
+
+	return nil
+}
+
+func (r SagaRepository) GetSagaStepsExecutionByExecutionID(
+	_ context.Context,
+	executionID uuid.UUID,
+) ([]entities.StepExecution, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return append([]entities.StepExecution{}, r.stepExecutions[executionID]...), nil
+}
+
+func (r SagaRepository) GetSagaStepsExecutionByExecutionIDs(
+	_ context.Context,
+	executionIDs []uuid.UUID,
+) ([]entities.StepExecution, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	steps := make([]entities.StepExecution, 0)
+	for _, executionID := range executionIDs {
+		steps = append(steps, r.stepExecutions[executionID]...)
+	}
+
+	return steps, nil
+}
+
+func (r SagaRepository) CreateSagaStepsExecution(
+	_ context.Context,
+	steps []entities.StepExecution,
+) ([]entities.StepExecution, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	savedSteps := make([]entities.StepExecution, 0, len(steps))
+	for _, step := range steps {
+		step.StepExecutionID = uuid.New()
+		r.stepExecutions[step.SagaExecutionID] = append(r.stepExecutions[step.SagaExecutionID], step)
+		savedSteps = append(savedSteps, step)
+	}
+	for executionID := range r.stepExecutions {
+		sortStepExecutions(r.stepExecutions[executionID])
+	}
+
+	return savedSteps, nil
+}
+
+func (r SagaRepository) SetSagaStepExecutionStatus(
+	_ context.Context,
+	status entities.StepExecutionStatus,
+	index int,
+	executionID uuid.UUID,
+) error {
+	return r.updateStepExecution(executionID, index, func(step *entities.StepExecution) {
+		step.Status = status
+		switch status {
+		case entities.StepExecutionStarted:
+			startedAt := now()
+			step.StartedAt = &startedAt
+		case entities.StepExecutionFinished, entities.StepExecutionError:
+			finishedAt := now()
+			step.FinishedAt = &finishedAt
+		}
+		if status == entities.StepExecutionError {
+			step.Failed = true
+		}
+	})
+}
+
+func (r SagaRepository) SetSagaStepExecutionOutput(
+	_ context.Context,
+	output []byte,
+	index int,
+	executionID uuid.UUID,
+) error {
+	return r.updateStepExecution(executionID, index, func(step *entities.StepExecution) {
+		step.Output = append([]byte{}, output...)
+	})
+}
+
+func (r SagaRepository) SetSagaStepExecutionError(
+	_ context.Context,
+	stepError entities.StepError,
+	index int,
+	executionID uuid.UUID,
+) error {
+	return r.updateStepExecution(executionID, index, func(step *entities.StepExecution) {
+		step.Error = &stepError
+	})
+}
+
+func (r SagaRepository) IncrementSagaStepExecutionAttempt(
+	_ context.Context,
+	index int,
+	executionID uuid.UUID,
+) (int, error) {
+	attempt := 0
+	err := r.updateStepExecution(executionID, index, func(step *entities.StepExecution) {
+		step.Attempt++
+		attempt = step.Attempt
+	})
+	if err != nil {
+		return 0, err
+	}
+	if attempt == 0 {
+		return 0, sagas.ErrStepExecutionNotFound
+	}
+
+	return attempt, nil
+}
+
+func (r SagaRepository) updateStepExecution(
+	executionID uuid.UUID,
+	index int,
+	update func(step *entities.StepExecution),
+) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// The steps are copied so the slices handed out before don't see the change
+	steps := append([]entities.StepExecution{}, r.stepExecutions[executionID]...)
+	for i := range steps {
+		if steps[i].Index == index {
+			update(&steps[i])
+		}
+	}
+	r.stepExecutions[executionID] = steps
+
+	return nil
+}
+
+func sortSagaSteps(steps []entities.SagaStep) {
+	sort.Slice(steps, func(i, j int) bool { return steps[i].Index < steps[j].Index })
+}
+
+func sortStepExecutions(steps []entities.StepExecution) {
+	sort.Slice(steps, func(i, j int) bool { return steps[i].Index < steps[j].Index })
+}
+
+func inTimeWindow(createdAt time.Time, createdAfter, createdBefore *time.Time) bool {
+	if createdAfter != nil && createdAt.Before(*createdAfter) {
+		return false
+	}
+	if createdBefore != nil && !createdAt.Before(*createdBefore) {
+		return false
+	}
+
+	return true
+}
+
+// isBeforeCursor tells whether the item comes after the cursor in the lists, sorted by creation date and id descending.
+func isBeforeCursor(createdAt time.Time, id uuid.UUID, cursor sagas.Cursor) bool {
+	if !createdAt.Equal(cursor.CreatedAt) {
+		return createdAt.Before(cursor.CreatedAt)
+	}
+
+	return bytes.Compare(id[:], cursor.ID[:]) < 0
+}
+
+// now drops the monotonic clock reading and the precision postgres doesn't keep, so cursors round trip.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+)
+
+func (r SagaRepository) GetSagaExecutionStatistics(
+	_ context.Context,
+	sagaID uuid.UUID,
+	from, to time.Time,
+) (sagas.ExecutionStatistics, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var statistics sagas.ExecutionStatistics
+	durations := make([]time.Duration, 0)
+	for _, execution := range r.windowExecutions(sagaID, from, to) {
+		statistics.Total++
+		switch execution.Status {
+		case entities.SagaExecutionRunning:
+			statistics.Running++
+		case entities.SagaExecutionCompensating:
+			statistics.Compensating++
+		case entities.SagaExecutionSucceeded:
+			statistics.Succeeded++
+		case entities.SagaExecutionFailed:
+			statistics.Failed++
+		}
+		if r.hasStepWithStatus(execution.SagaExecutionID, entities.StepExecutionCompensated) {
+			statistics.Compensated++
+		}
+		if execution.FinishedAt != nil {
+			durations = append(durations, execution.FinishedAt.Sub(execution.CreatedAt))
+		}
+	}
+	statistics.Duration = durationPercentiles(durations)
+
+	return statistics, nil
+}
+
+func (r SagaRepository) GetSagaStepStatistics(
+	_ context.Context,
+	sagaID uuid.UUID,
+	from, to time.Time,
+) ([]sagas.StepStatistics, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	statisticsByIndex := make(map[int]*sagas.StepStatistics)
+	durationsByIndex := make(map[int][]time.Duration)
+	for _, execution := range r.windowExecutions(sagaID, from, to) {
+		for _, step := range r.stepExecutions[execution.SagaExecutionID] {
+			if step.StartedAt == nil {
+				continue
+			}
+
+			statistics, ok := statisticsByIndex[step.Index]
+			if !ok {
+				statistics = &sagas.StepStatistics{Index: step.Index, Name: step.Name}
+				statisticsByIndex[step.Index] = statistics
+			}
+			statistics.Total++
+			if step.Failed {
+				statistics.Failed++
+			}
+			if step.FinishedAt != nil {
+				durationsByIndex[step.Index] = append(durationsByIndex[step.Index], step.FinishedAt.Sub(*step.StartedAt))
+			}
+		}
+	}
+
+	steps := make([]sagas.StepStatistics, 0, len(statisticsByIndex))
+	for index, statistics := range statisticsByIndex {
+		statistics.Duration = durationPercentiles(durationsByIndex[index])
+		steps = append(steps, *statistics)
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].Index < steps[j].Index })
+
+	return steps, nil
+}
+
+func (r SagaRepository) windowExecutions(sagaID uuid.UUID, from, to time.Time) []entities.SagaExecution {
+	executions := make([]entities.SagaExecution, 0)
+	for _, execution := range r.executions {
+		if execution.SagaID == sagaID && inTimeWindow(execution.CreatedAt, &from, &to) {
+			executions = append(executions, execution)
+		}
+	}
+
+	return executions
+}
+
+func durationPercentiles(durations []time.Duration) sagas.DurationPercentiles {
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	return sagas.DurationPercentiles{
+		P50: percentile(durations, 0.5),
+		P95: percentile(durations, 0.95),
+		P99: percentile(durations, 0.99),
+	}
+}
+
+// percentile interpolates between the closest durations, like postgres percentile_cont.
+func percentile(sorted []time.Duration, fraction float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	position := fraction * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	weight := position - float64(lower)
+
+	return sorted[lower] + time.Duration(weight*float64(sorted[upper]-sorted[lower]))
+}
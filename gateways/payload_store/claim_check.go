@@ -0,0 +1,103 @@
+package payload_store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/gateways/encryption"
+)
+
+// StepPayloadsPrefix is the key prefix of the offloaded payloads of an execution steps, they're discarded together.
+func StepPayloadsPrefix(sagaName string, executionID uuid.UUID) string {
+	return fmt.Sprintf("sagas/%s/executions/%s/steps/", sagaName, executionID)
+}
+
+// ClaimCheck moves the payloads bigger than the threshold to the store, leaving only a reference behind.
+// The zero value keeps every payload inline.
+//
+// Stored payloads are encrypted as a whole with the keyring, when it has a current key, since they hold the decrypted
+// sensitive fields sent to the steps. Workers resolving them need the same keys.
+type ClaimCheck struct {
+	store     Store
+	threshold int
+	keyring   encryption.Keyring
+}
+
+func NewClaimCheck(store Store, threshold int, keyring encryption.Keyring) ClaimCheck {
+	return ClaimCheck{store: store, threshold: threshold, keyring: keyring}
+}
+
+func (c ClaimCheck) Enabled() bool {
+	return c.store != nil && c.threshold > 0
+}
+
+// Offload stores the payload when it's too big, returning an empty payload and its reference.
+func (c ClaimCheck) Offload(ctx context.Context, key string, payload []byte) ([]byte, string, error) {
+	if !c.Enabled() || len(payload) <= c.threshold {
+		return payload, "", nil
+	}
+
+	encryptedPayload, err := c.keyring.EncryptDocument(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("error encrypting offloaded payload: %w", err)
+	}
+
+	reference, err := c.store.Put(ctx, key, encryptedPayload)
+	if err != nil {
+		return nil, "", fmt.Errorf("error offloading payload: %w", err)
+	}
+
+	return nil, reference, nil
+}
+
+// Resolve returns the inline payload or loads it from the store when there's a reference.
+func (c ClaimCheck) Resolve(ctx context.Context, payload []byte, reference string) ([]byte, error) {
+	if reference == "" {
+		return payload, nil
+	}
+	if c.store == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownReference, reference)
+	}
+
+	storedPayload, err := c.store.Get(ctx, reference)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving payload: %w", err)
+	}
+
+	decryptedPayload, err := c.keyring.DecryptDocument(storedPayload)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting offloaded payload: %w", err)
+	}
+
+	return decryptedPayload, nil
+}
+
+// Discard deletes a stored payload, e.g. one whose execution failed to be saved.
+func (c ClaimCheck) Discard(ctx context.Context, reference string) error {
+	if reference == "" {
+		return nil
+	}
+	if c.store == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownReference, reference)
+	}
+
+	if err := c.store.Delete(ctx, reference); err != nil {
+		return fmt.Errorf("error discarding payload: %w", err)
+	}
+
+	return nil
+}
+
+// DiscardPrefix deletes every stored payload whose key starts with the prefix.
+func (c ClaimCheck) DiscardPrefix(ctx context.Context, keyPrefix string) error {
+	if c.store == nil {
+		return nil
+	}
+
+	if err := c.store.DeletePrefix(ctx, keyPrefix); err != nil {
+		return fmt.Errorf("error discarding payloads: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package payload_store
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const fileSystemScheme = "file"
+
+type fileSystemStore struct {
+	directory string
+}
+
+func NewFileSystemStore(directory string) (Store, error) {
+	absoluteDirectory, err := filepath.Abs(directory)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving payload store directory: %w", err)
+	}
+
+	if err := os.MkdirAll(absoluteDirectory, 0o750); err != nil {
+		return nil, fmt.Errorf("error creating payload store directory: %w", err)
+	}
+
+	return fileSystemStore{directory: absoluteDirectory}, nil
+}
+
+func (s fileSystemStore) Put(_ context.Context, key string, payload []byte) (string, error) {
+	path, err := s.path(filepath.FromSlash(key))
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", fmt.Errorf("error creating payload directory: %w", err)
+	}
+	if err := ioutil.WriteFile(path, payload, 0o640); err != nil {
+		return "", fmt.Errorf("error writing payload: %w", err)
+	}
+
+	return fileSystemScheme + "://" + filepath.ToSlash(path), nil
+}
+
+func (s fileSystemStore) Get(_ context.Context, reference string) ([]byte, error) {
+	path, err := s.referencePath(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading payload: %w", err)
+	}
+
+	return payload, nil
+}
+
+func (s fileSystemStore) Delete(_ context.Context, reference string) error {
+	path, err := s.referencePath(reference)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error deleting payload: %w", err)
+	}
+
+	return nil
+}
+
+func (s fileSystemStore) DeletePrefix(_ context.Context, keyPrefix string) error {
+	path, err := s.path(filepath.FromSlash(keyPrefix))
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("error deleting payloads: %w", err)
+	}
+
+	return nil
+}
+
+func (s fileSystemStore) referencePath(reference string) (string, error) {
+	prefix := fileSystemScheme + "://"
+	if !strings.HasPrefix(reference, prefix) {
+		return "", ErrUnknownReference
+	}
+
+	relativePath, err := filepath.Rel(s.directory, filepath.FromSlash(strings.TrimPrefix(reference, prefix)))
+	if err != nil {
+		return "", ErrUnknownReference
+	}
+
+	return s.path(relativePath)
+}
+
+// path makes sure the key can't escape from the store directory.
+func (s fileSystemStore) path(key string) (string, error) {
+	path := filepath.Join(s.directory, key)
+	if !strings.HasPrefix(path, s.directory+string(filepath.Separator)) {
+		return "", ErrUnknownReference
+	}
+
+	return path, nil
+}
@@ -0,0 +1,112 @@
+package payload_store
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const s3Scheme = "s3"
+
+type s3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Store creates a store on top of any S3 compatible storage, e.g. a local MinIO.
+func NewS3Store(client *minio.Client, bucket string) Store {
+	return s3Store{client: client, bucket: bucket}
+}
+
+func newS3StoreFromURL(storeURL *url.URL) (Store, error) {
+	accessKey := storeURL.User.Username()
+	secretKey, _ := storeURL.User.Password()
+
+	client, err := minio.New(storeURL.Host, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: storeURL.Query().Get("ssl") != "false",
+		Region: storeURL.Query().Get("region"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating s3 client: %w", err)
+	}
+
+	bucket := strings.Trim(storeURL.Path, "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("%w: missing s3 bucket", ErrUnsupportedStore)
+	}
+
+	return NewS3Store(client, bucket), nil
+}
+
+func (s s3Store) Put(ctx context.Context, key string, payload []byte) (string, error) {
+	_, err := s.client.PutObject(
+		ctx,
+		s.bucket,
+		key,
+		bytes.NewReader(payload),
+		int64(len(payload)),
+		minio.PutObjectOptions{ContentType: "application/json"},
+	)
+	if err != nil {
+		return "", fmt.Errorf("error uploading payload: %w", err)
+	}
+
+	return fmt.Sprintf("%s://%s/%s", s3Scheme, s.bucket, key), nil
+}
+
+func (s s3Store) Get(ctx context.Context, reference string) ([]byte, error) {
+	prefix := fmt.Sprintf("%s://%s/", s3Scheme, s.bucket)
+	if !strings.HasPrefix(reference, prefix) {
+		return nil, ErrUnknownReference
+	}
+
+	object, err := s.client.GetObject(ctx, s.bucket, strings.TrimPrefix(reference, prefix), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error downloading payload: %w", err)
+	}
+	defer object.Close()
+
+	payload, err := ioutil.ReadAll(object)
+	if err != nil {
+		return nil, fmt.Errorf("error reading payload: %w", err)
+	}
+
+	return payload, nil
+}
+
+func (s s3Store) Delete(ctx context.Context, reference string) error {
+	prefix := fmt.Sprintf("%s://%s/", s3Scheme, s.bucket)
+	if !strings.HasPrefix(reference, prefix) {
+		return ErrUnknownReference
+	}
+
+	err := s.client.RemoveObject(ctx, s.bucket, strings.TrimPrefix(reference, prefix), minio.RemoveObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("error deleting payload: %w", err)
+	}
+
+	return nil
+}
+
+func (s s3Store) DeletePrefix(ctx context.Context, keyPrefix string) error {
+	objects := s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: keyPrefix, Recursive: true})
+	for object := range objects {
+		if object.Err != nil {
+			return fmt.Errorf("error listing payloads: %w", object.Err)
+		}
+
+		err := s.client.RemoveObject(ctx, s.bucket, object.Key, minio.RemoveObjectOptions{})
+		if err != nil {
+			return fmt.Errorf("error deleting payload: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package payload_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+var ErrUnknownReference = errors.New("payload reference does not belong to this store")
+var ErrUnsupportedStore = errors.New("unsupported payload store")
+
+// Store keeps payloads outside the database and the messages, they're identified by a reference.
+type Store interface {
+	Put(ctx context.Context, key string, payload []byte) (string, error)
+	Get(ctx context.Context, reference string) ([]byte, error)
+	Delete(ctx context.Context, reference string) error
+	DeletePrefix(ctx context.Context, keyPrefix string) error
+}
+
+// Open creates the store described by the URL:
+//
+//	file:///var/lib/sukuna/payloads
+//	s3://access-key:secret-key@localhost:9000/bucket?ssl=false
+func Open(rawURL string) (Store, error) {
+	storeURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing payload store url: %w", err)
+	}
+
+	switch storeURL.Scheme {
+	case fileSystemScheme:
+		return NewFileSystemStore(storeURL.Path)
+	case s3Scheme:
+		return newS3StoreFromURL(storeURL)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedStore, storeURL.Scheme)
+	}
+}
@@ -0,0 +1,4 @@
+ALTER TABLE step_executions DROP COLUMN IF EXISTS output;
+ALTER TABLE step_executions DROP COLUMN IF EXISTS input_mapping;
+
+ALTER TABLE saga_steps DROP COLUMN IF EXISTS input_mapping;
@@ -0,0 +1,4 @@
+ALTER TABLE saga_steps ADD COLUMN input_mapping JSONB;
+
+ALTER TABLE step_executions ADD COLUMN input_mapping JSONB;
+ALTER TABLE step_executions ADD COLUMN output JSONB;
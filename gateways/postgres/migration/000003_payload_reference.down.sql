@@ -0,0 +1 @@
+ALTER TABLE saga_executions DROP COLUMN IF EXISTS payload_reference;
@@ -0,0 +1 @@
+ALTER TABLE saga_executions ADD COLUMN payload_reference TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS step_executions_saga_execution_id_idx;
+DROP INDEX IF EXISTS saga_executions_saga_id_created_at_idx;
+
+DROP TABLE IF EXISTS archived_executions;
+
+ALTER TABLE sagas DROP COLUMN IF EXISTS retention_days;
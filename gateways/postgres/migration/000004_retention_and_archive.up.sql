@@ -0,0 +1,11 @@
+ALTER TABLE sagas ADD COLUMN retention_days INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE archived_executions (
+    saga_execution_id uuid PRIMARY KEY,
+    saga_id uuid NOT NULL,
+    location TEXT NOT NULL,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX saga_executions_saga_id_created_at_idx ON saga_executions (saga_id, created_at);
+CREATE INDEX step_executions_saga_execution_id_idx ON step_executions (saga_execution_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS archived_executions_saga_id_idx;
+DROP INDEX IF EXISTS saga_steps_saga_id_idx;
+
+ALTER TABLE sagas DROP COLUMN IF EXISTS deprecated_at;
+ALTER TABLE sagas DROP COLUMN IF EXISTS description;
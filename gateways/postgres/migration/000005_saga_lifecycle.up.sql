@@ -0,0 +1,5 @@
+ALTER TABLE sagas ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE sagas ADD COLUMN deprecated_at TIMESTAMP;
+
+CREATE INDEX saga_steps_saga_id_idx ON saga_steps (saga_id);
+CREATE INDEX archived_executions_saga_id_idx ON archived_executions (saga_id);
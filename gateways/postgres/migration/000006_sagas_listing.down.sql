@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS sagas_formatted_name_idx;
+DROP INDEX IF EXISTS sagas_created_at_saga_id_idx;
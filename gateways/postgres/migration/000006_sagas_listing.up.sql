@@ -0,0 +1,2 @@
+CREATE INDEX sagas_created_at_saga_id_idx ON sagas (created_at DESC, saga_id DESC);
+CREATE INDEX sagas_formatted_name_idx ON sagas (formatted_name);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS step_executions_status_saga_execution_id_idx;
+DROP INDEX IF EXISTS saga_executions_saga_id_status_created_at_idx;
+DROP INDEX IF EXISTS saga_executions_status_created_at_idx;
+DROP INDEX IF EXISTS saga_executions_created_at_id_idx;
+
+ALTER TABLE saga_executions DROP COLUMN IF EXISTS status;
@@ -0,0 +1,22 @@
+ALTER TABLE saga_executions ADD COLUMN status TEXT NOT NULL DEFAULT 'running';
+
+UPDATE saga_executions se SET status = CASE
+    WHEN EXISTS (
+        SELECT 1 FROM step_executions ste
+        WHERE ste.saga_execution_id = se.saga_execution_id AND ste.status = 'in_compensation'
+    ) THEN 'compensating'
+    WHEN EXISTS (
+        SELECT 1 FROM step_executions ste
+        WHERE ste.saga_execution_id = se.saga_execution_id AND ste.status = 'started'
+    ) THEN 'running'
+    WHEN EXISTS (
+        SELECT 1 FROM step_executions ste
+        WHERE ste.saga_execution_id = se.saga_execution_id AND ste.status = 'error'
+    ) THEN 'failed'
+    ELSE 'succeeded'
+END;
+
+CREATE INDEX saga_executions_created_at_id_idx ON saga_executions (created_at DESC, saga_execution_id DESC);
+CREATE INDEX saga_executions_status_created_at_idx ON saga_executions (status, created_at DESC);
+CREATE INDEX saga_executions_saga_id_status_created_at_idx ON saga_executions (saga_id, status, created_at DESC);
+CREATE INDEX step_executions_status_saga_execution_id_idx ON step_executions (status, saga_execution_id);
@@ -0,0 +1,4 @@
+ALTER TABLE step_executions DROP COLUMN IF EXISTS finished_at;
+ALTER TABLE step_executions DROP COLUMN IF EXISTS started_at;
+
+ALTER TABLE saga_executions DROP COLUMN IF EXISTS finished_at;
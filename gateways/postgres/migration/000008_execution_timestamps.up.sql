@@ -0,0 +1,4 @@
+ALTER TABLE saga_executions ADD COLUMN finished_at TIMESTAMP;
+
+ALTER TABLE step_executions ADD COLUMN started_at TIMESTAMP;
+ALTER TABLE step_executions ADD COLUMN finished_at TIMESTAMP;
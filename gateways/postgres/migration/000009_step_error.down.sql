@@ -0,0 +1 @@
+ALTER TABLE step_executions DROP COLUMN IF EXISTS error;
@@ -0,0 +1 @@
+ALTER TABLE step_executions ADD COLUMN error JSONB;
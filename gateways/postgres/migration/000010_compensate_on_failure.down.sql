@@ -0,0 +1,2 @@
+ALTER TABLE step_executions DROP COLUMN IF EXISTS compensate_on_failure;
+ALTER TABLE saga_steps DROP COLUMN IF EXISTS compensate_on_failure;
@@ -0,0 +1,2 @@
+ALTER TABLE saga_steps ADD COLUMN compensate_on_failure BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE step_executions ADD COLUMN compensate_on_failure BOOLEAN NOT NULL DEFAULT FALSE;
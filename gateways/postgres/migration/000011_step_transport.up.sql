@@ -0,0 +1,2 @@
+ALTER TABLE saga_steps ADD COLUMN transport TEXT NOT NULL DEFAULT 'kafka';
+ALTER TABLE step_executions ADD COLUMN transport TEXT NOT NULL DEFAULT 'kafka';
@@ -0,0 +1,2 @@
+ALTER TABLE step_executions DROP COLUMN IF EXISTS transport_config;
+ALTER TABLE saga_steps DROP COLUMN IF EXISTS transport_config;
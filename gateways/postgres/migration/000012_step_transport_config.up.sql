@@ -0,0 +1,2 @@
+ALTER TABLE saga_steps ADD COLUMN transport_config JSONB;
+ALTER TABLE step_executions ADD COLUMN transport_config JSONB;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS tasks_queue_created_at_idx;
+DROP INDEX IF EXISTS tasks_token_idx;
+
+DROP TABLE IF EXISTS tasks;
@@ -0,0 +1,14 @@
+CREATE TABLE tasks (
+    task_id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    token uuid NOT NULL DEFAULT uuid_generate_v4(),
+    queue TEXT NOT NULL,
+    saga_name TEXT NOT NULL,
+    saga_execution_id uuid NOT NULL,
+    step_index INTEGER NOT NULL,
+    step JSONB NOT NULL,
+    leased_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX tasks_token_idx ON tasks (token);
+CREATE INDEX tasks_queue_created_at_idx ON tasks (queue, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS step_executions_failed_idx;
+
+ALTER TABLE step_executions DROP COLUMN IF EXISTS failed;
@@ -0,0 +1,5 @@
+ALTER TABLE step_executions ADD COLUMN failed BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE step_executions SET failed = TRUE WHERE status = 'error' OR error IS NOT NULL;
+
+CREATE INDEX step_executions_failed_idx ON step_executions (saga_execution_id) WHERE failed;
@@ -0,0 +1,3 @@
+ALTER TABLE step_executions ADD COLUMN attempt INTEGER NOT NULL DEFAULT 0;
+
+UPDATE step_executions SET attempt = 1 WHERE status <> 'registered';
@@ -3,38 +3,78 @@
 package postgres
 
 import (
+	"database/sql"
 	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type ArchivedExecution struct {
+	SagaExecutionID uuid.UUID `db:"saga_execution_id"`
+	SagaID          uuid.UUID `db:"saga_id"`
+	Location        string    `db:"location"`
+	ArchivedAt      time.Time `db:"archived_at"`
+}
+
 type Saga struct {
 	SagaID        uuid.UUID       `db:"saga_id"`
 	Name          string          `db:"name"`
 	FormattedName string          `db:"formatted_name"`
 	Payload       json.RawMessage `db:"payload"`
 	CreatedAt     time.Time       `db:"created_at"`
+	RetentionDays int32           `db:"retention_days"`
+	Description   string          `db:"description"`
+	DeprecatedAt  sql.NullTime    `db:"deprecated_at"`
 }
 
 type SagaExecution struct {
-	SagaExecutionID uuid.UUID       `db:"saga_execution_id"`
-	SagaID          uuid.UUID       `db:"saga_id"`
-	Payload         json.RawMessage `db:"payload"`
-	CreatedAt       time.Time       `db:"created_at"`
+	SagaExecutionID  uuid.UUID       `db:"saga_execution_id"`
+	SagaID           uuid.UUID       `db:"saga_id"`
+	Payload          json.RawMessage `db:"payload"`
+	CreatedAt        time.Time       `db:"created_at"`
+	PayloadReference string          `db:"payload_reference"`
+	Status           string          `db:"status"`
+	FinishedAt       sql.NullTime    `db:"finished_at"`
 }
 
 type SagaStep struct {
-	StepID uuid.UUID `db:"step_id"`
-	SagaID uuid.UUID `db:"saga_id"`
-	Index  int32     `db:"index"`
-	Name   string    `db:"name"`
+	StepID              uuid.UUID       `db:"step_id"`
+	SagaID              uuid.UUID       `db:"saga_id"`
+	Index               int32           `db:"index"`
+	Name                string          `db:"name"`
+	InputMapping        json.RawMessage `db:"input_mapping"`
+	CompensateOnFailure bool            `db:"compensate_on_failure"`
+	Transport           string          `db:"transport"`
+	TransportConfig     json.RawMessage `db:"transport_config"`
 }
 
 type StepExecution struct {
-	StepExecutionID uuid.UUID `db:"step_execution_id"`
-	SagaExecutionID uuid.UUID `db:"saga_execution_id"`
-	Index           int32     `db:"index"`
-	Name            string    `db:"name"`
-	Status          string    `db:"status"`
+	StepExecutionID     uuid.UUID       `db:"step_execution_id"`
+	SagaExecutionID     uuid.UUID       `db:"saga_execution_id"`
+	Index               int32           `db:"index"`
+	Name                string          `db:"name"`
+	Status              string          `db:"status"`
+	InputMapping        json.RawMessage `db:"input_mapping"`
+	Output              json.RawMessage `db:"output"`
+	StartedAt           sql.NullTime    `db:"started_at"`
+	FinishedAt          sql.NullTime    `db:"finished_at"`
+	Error               json.RawMessage `db:"error"`
+	CompensateOnFailure bool            `db:"compensate_on_failure"`
+	Transport           string          `db:"transport"`
+	TransportConfig     json.RawMessage `db:"transport_config"`
+	Failed              bool            `db:"failed"`
+	Attempt             int32           `db:"attempt"`
+}
+
+type Task struct {
+	TaskID          uuid.UUID       `db:"task_id"`
+	Token           uuid.UUID       `db:"token"`
+	Queue           string          `db:"queue"`
+	SagaName        string          `db:"saga_name"`
+	SagaExecutionID uuid.UUID       `db:"saga_execution_id"`
+	StepIndex       int32           `db:"step_index"`
+	Step            json.RawMessage `db:"step"`
+	LeasedUntil     sql.NullTime    `db:"leased_until"`
+	CreatedAt       time.Time       `db:"created_at"`
 }
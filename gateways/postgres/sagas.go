@@ -2,53 +2,179 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
 	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/retention"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/gateways/encryption"
+	"github.com/thepabloaguilar/sukuna/gateways/payload_store"
 )
 
+// offloadedPayload is kept in the payload column when the payload lives in the payload store
+var offloadedPayload = []byte("null")
+
 type SagaRepository struct {
-	q Queries
+	q          Queries
+	keyring    encryption.Keyring
+	claimCheck payload_store.ClaimCheck
 }
 
-func NewSagaRepository(q Queries) SagaRepository {
-	return SagaRepository{q: q}
+func NewSagaRepository(q Queries, keyring encryption.Keyring, claimCheck payload_store.ClaimCheck) SagaRepository {
+	return SagaRepository{q: q, keyring: keyring, claimCheck: claimCheck}
 }
 
 func (r SagaRepository) GetSaga(ctx context.Context, sagaID uuid.UUID) (entities.Saga, error) {
 	dbSaga, err := r.q.GetSaga(ctx, sagaID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Saga{}, sagas.ErrSagaNotFound
+	}
 	if err != nil {
 		return entities.Saga{}, err
 	}
 
-	return entities.Saga{
-		SagaID:        dbSaga.SagaID,
-		Name:          dbSaga.Name,
-		FormattedName: dbSaga.FormattedName,
-		Payload:       dbSaga.Payload,
-		CreatedAt:     dbSaga.CreatedAt,
-	}, nil
+	return toSagaEntity(dbSaga), nil
+}
+
+func (r SagaRepository) ListSagas(ctx context.Context, filter sagas.SagaFilter) ([]entities.Saga, error) {
+	params := ListSagasParams{
+		Name:          filter.Name,
+		FormattedName: filter.FormattedName,
+		MaxSagas:      int32(filter.Limit),
+	}
+	if filter.CreatedAfter != nil {
+		params.FilterCreatedAfter = true
+		params.CreatedAfter = *filter.CreatedAfter
+	}
+	if filter.CreatedBefore != nil {
+		params.FilterCreatedBefore = true
+		params.CreatedBefore = *filter.CreatedBefore
+	}
+	if filter.Deprecated != nil {
+		params.FilterDeprecated = true
+		params.Deprecated = *filter.Deprecated
+	}
+	if filter.After != nil {
+		params.UseCursor = true
+		params.CursorCreatedAt = filter.After.CreatedAt
+		params.CursorID = filter.After.ID
+	}
+
+	dbSagas, err := r.q.ListSagas(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("error listing sagas: %w", err)
+	}
+
+	foundSagas := make([]entities.Saga, 0, len(dbSagas))
+	for _, dbSaga := range dbSagas {
+		foundSagas = append(foundSagas, toSagaEntity(Saga{
+			SagaID:        dbSaga.SagaID,
+			Name:          dbSaga.Name,
+			FormattedName: dbSaga.FormattedName,
+			CreatedAt:     dbSaga.CreatedAt,
+			RetentionDays: dbSaga.RetentionDays,
+			Description:   dbSaga.Description,
+			DeprecatedAt:  dbSaga.DeprecatedAt,
+		}))
+	}
+
+	return foundSagas, nil
 }
 
 func (r SagaRepository) CreateSaga(ctx context.Context, saga entities.Saga) (entities.Saga, error) {
 	args := CreateSagaParams{
 		Name:          saga.Name,
 		FormattedName: saga.FormattedName,
+		Description:   saga.Description,
 		Payload:       saga.Payload,
+		RetentionDays: int32(saga.RetentionDays),
 	}
 	dbSaga, err := r.q.CreateSaga(ctx, args)
 	if err != nil {
 		return entities.Saga{}, err
 	}
 
-	return entities.Saga{
+	return toSagaEntity(dbSaga), nil
+}
+
+func (r SagaRepository) UpdateSaga(ctx context.Context, saga entities.Saga) (entities.Saga, error) {
+	args := UpdateSagaParams{
+		SagaID:        saga.SagaID,
+		Name:          saga.Name,
+		Description:   saga.Description,
+		RetentionDays: int32(saga.RetentionDays),
+	}
+	dbSaga, err := r.q.UpdateSaga(ctx, args)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Saga{}, sagas.ErrSagaNotFound
+	}
+	if err != nil {
+		return entities.Saga{}, fmt.Errorf("error updating saga: %w", err)
+	}
+
+	return toSagaEntity(dbSaga), nil
+}
+
+func (r SagaRepository) DeprecateSaga(ctx context.Context, sagaID uuid.UUID) (entities.Saga, error) {
+	dbSaga, err := r.q.DeprecateSaga(ctx, sagaID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Saga{}, sagas.ErrSagaNotFound
+	}
+	if err != nil {
+		return entities.Saga{}, fmt.Errorf("error deprecating saga: %w", err)
+	}
+
+	return toSagaEntity(dbSaga), nil
+}
+
+// DeleteSaga deletes the saga and its steps only when it has no executions, archived ones included.
+func (r SagaRepository) DeleteSaga(ctx context.Context, sagaID uuid.UUID) error {
+	deleted, err := r.q.DeleteSagaWithoutExecutions(ctx, sagaID)
+	if err != nil {
+		return fmt.Errorf("error deleting saga: %w", err)
+	}
+	if deleted == 0 {
+		return sagas.ErrSagaHasExecutions
+	}
+
+	return nil
+}
+
+func (r SagaRepository) GetSagasWithRetention(ctx context.Context) ([]entities.Saga, error) {
+	dbSagas, err := r.q.GetSagasWithRetention(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sagas with retention: %w", err)
+	}
+
+	retainedSagas := make([]entities.Saga, 0, len(dbSagas))
+	for _, dbSaga := range dbSagas {
+		retainedSagas = append(retainedSagas, toSagaEntity(dbSaga))
+	}
+
+	return retainedSagas, nil
+}
+
+func toSagaEntity(dbSaga Saga) entities.Saga {
+	saga := entities.Saga{
 		SagaID:        dbSaga.SagaID,
 		Name:          dbSaga.Name,
 		FormattedName: dbSaga.FormattedName,
+		Description:   dbSaga.Description,
 		Payload:       dbSaga.Payload,
+		RetentionDays: int(dbSaga.RetentionDays),
 		CreatedAt:     dbSaga.CreatedAt,
-	}, nil
+	}
+	if dbSaga.DeprecatedAt.Valid {
+		saga.DeprecatedAt = &dbSaga.DeprecatedAt.Time
+	}
+
+	return saga
 }
 
 func (r SagaRepository) GetSagaStepsBySagaID(
@@ -63,10 +189,41 @@ func (r SagaRepository) GetSagaStepsBySagaID(
 	sagaSteps := make([]entities.SagaStep, 0, len(dbSteps))
 	for _, step := range dbSteps {
 		sagaStep := entities.SagaStep{
-			StepID: step.StepID,
-			SagaID: step.SagaID,
-			Index:  int(step.Index),
-			Name:   step.Name,
+			StepID:              step.StepID,
+			SagaID:              step.SagaID,
+			Index:               int(step.Index),
+			Name:                step.Name,
+			InputMapping:        step.InputMapping,
+			CompensateOnFailure: step.CompensateOnFailure,
+			Transport:           entities.StepTransport(step.Transport),
+			TransportConfig:     step.TransportConfig,
+		}
+		sagaSteps = append(sagaSteps, sagaStep)
+	}
+
+	return sagaSteps, nil
+}
+
+func (r SagaRepository) GetSagaStepsBySagaIDs(
+	ctx context.Context,
+	sagaIDs []uuid.UUID,
+) ([]entities.SagaStep, error) {
+	dbSteps, err := r.q.GetSagaStepsBySagaIDs(ctx, sagaIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error getting saga steps: %w", err)
+	}
+
+	sagaSteps := make([]entities.SagaStep, 0, len(dbSteps))
+	for _, step := range dbSteps {
+		sagaStep := entities.SagaStep{
+			StepID:              step.StepID,
+			SagaID:              step.SagaID,
+			Index:               int(step.Index),
+			Name:                step.Name,
+			InputMapping:        step.InputMapping,
+			CompensateOnFailure: step.CompensateOnFailure,
+			Transport:           entities.StepTransport(step.Transport),
+			TransportConfig:     step.TransportConfig,
 		}
 		sagaSteps = append(sagaSteps, sagaStep)
 	}
@@ -79,15 +236,23 @@ func (r SagaRepository) CreateSagaSteps(
 	steps []entities.SagaStep,
 ) ([]entities.SagaStep, error) {
 	args := CreateSagaStepsParams{
-		SagaIds: make([]uuid.UUID, 0, len(steps)),
-		Indexes: make([]int32, 0, len(steps)),
-		Names:   make([]string, 0, len(steps)),
+		SagaIds:              make([]uuid.UUID, 0, len(steps)),
+		Indexes:              make([]int32, 0, len(steps)),
+		Names:                make([]string, 0, len(steps)),
+		InputMappings:        make([]json.RawMessage, 0, len(steps)),
+		CompensateOnFailures: make([]bool, 0, len(steps)),
+		Transports:           make([]string, 0, len(steps)),
+		TransportConfigs:     make([]json.RawMessage, 0, len(steps)),
 	}
 
 	for _, step := range steps {
 		args.SagaIds = append(args.SagaIds, step.SagaID)
 		args.Indexes = append(args.Indexes, int32(step.Index))
 		args.Names = append(args.Names, step.Name)
+		args.InputMappings = append(args.InputMappings, step.InputMapping)
+		args.CompensateOnFailures = append(args.CompensateOnFailures, step.CompensateOnFailure)
+		args.Transports = append(args.Transports, string(step.Transport))
+		args.TransportConfigs = append(args.TransportConfigs, step.TransportConfig)
 	}
 
 	dbSteps, err := r.q.CreateSagaSteps(ctx, args)
@@ -98,10 +263,14 @@ func (r SagaRepository) CreateSagaSteps(
 	savedSteps := make([]entities.SagaStep, 0, len(steps))
 	for _, step := range dbSteps {
 		savedSteps = append(savedSteps, entities.SagaStep{
-			StepID: step.StepID,
-			SagaID: step.SagaID,
-			Index:  int(step.Index),
-			Name:   step.Name,
+			StepID:              step.StepID,
+			SagaID:              step.SagaID,
+			Index:               int(step.Index),
+			Name:                step.Name,
+			InputMapping:        step.InputMapping,
+			CompensateOnFailure: step.CompensateOnFailure,
+			Transport:           entities.StepTransport(step.Transport),
+			TransportConfig:     step.TransportConfig,
 		})
 	}
 
@@ -113,39 +282,191 @@ func (r SagaRepository) GetSagaExecution(
 	executionID uuid.UUID,
 ) (entities.SagaExecution, error) {
 	dbExecution, err := r.q.GetSagaExecution(ctx, executionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.SagaExecution{}, sagas.ErrSagaExecutionNotFound
+	}
 	if err != nil {
 		return entities.SagaExecution{}, fmt.Errorf("error getting saga execution info: %w", err)
 	}
 
-	return entities.SagaExecution{
+	payload, err := r.readExecutionPayload(ctx, dbExecution)
+	if err != nil {
+		return entities.SagaExecution{}, err
+	}
+
+	return toSagaExecutionEntity(dbExecution, payload), nil
+}
+
+func toSagaExecutionEntity(dbExecution SagaExecution, payload []byte) entities.SagaExecution {
+	execution := entities.SagaExecution{
 		SagaExecutionID: dbExecution.SagaExecutionID,
 		SagaID:          dbExecution.SagaID,
-		Payload:         dbExecution.Payload,
+		Payload:         payload,
+		Status:          entities.SagaExecutionStatus(dbExecution.Status),
 		CreatedAt:       dbExecution.CreatedAt,
-	}, nil
+	}
+	if dbExecution.FinishedAt.Valid {
+		execution.FinishedAt = &dbExecution.FinishedAt.Time
+	}
+
+	return execution
+}
+
+// readExecutionPayload loads the payload from the payload store, when needed, and decrypts its sensitive fields.
+func (r SagaRepository) readExecutionPayload(ctx context.Context, dbExecution SagaExecution) ([]byte, error) {
+	payload, err := r.claimCheck.Resolve(ctx, dbExecution.Payload, dbExecution.PayloadReference)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := r.sensitiveFields(ctx, dbExecution.SagaID)
+	if err != nil {
+		return nil, err
+	}
+	payload, err = sagas.TransformFields(payload, fields, r.keyring.Decrypt)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting sensitive fields: %w", err)
+	}
+
+	return payload, nil
 }
 
 func (r SagaRepository) CreateSagaExecution(
 	ctx context.Context,
 	execution entities.SagaExecution,
 ) (entities.SagaExecution, error) {
+	payload, err := r.encryptSensitiveFields(ctx, execution.SagaID, execution.Payload)
+	if err != nil {
+		return entities.SagaExecution{}, err
+	}
+
+	key := fmt.Sprintf("sagas/%s/executions/%s.json", execution.SagaID, uuid.New())
+	payload, reference, err := r.claimCheck.Offload(ctx, key, payload)
+	if err != nil {
+		return entities.SagaExecution{}, err
+	}
+	if reference != "" {
+		payload = offloadedPayload
+	}
+
 	args := CreateSagaExecutionParams{
-		SagaID:  execution.SagaID,
-		Payload: execution.Payload,
+		SagaID:           execution.SagaID,
+		Payload:          payload,
+		PayloadReference: reference,
 	}
 	savedExecution, err := r.q.CreateSagaExecution(ctx, args)
 	if err != nil {
+		// The offloaded payload would be left behind without any execution pointing to it
+		if discardErr := r.claimCheck.Discard(ctx, reference); discardErr != nil {
+			log.Printf("error discarding the payload %s of an unsaved execution: %v", reference, discardErr)
+		}
 		return entities.SagaExecution{}, fmt.Errorf("error saving saga execution: %w", err)
 	}
 
 	return entities.SagaExecution{
 		SagaExecutionID: savedExecution.SagaExecutionID,
 		SagaID:          savedExecution.SagaID,
-		Payload:         savedExecution.Payload,
+		Payload:         execution.Payload,
+		Status:          entities.SagaExecutionStatus(savedExecution.Status),
 		CreatedAt:       savedExecution.CreatedAt,
 	}, err
 }
 
+func (r SagaRepository) ListSagaExecutions(
+	ctx context.Context,
+	filter sagas.SagaExecutionFilter,
+) ([]entities.SagaExecution, error) {
+	params := ListSagaExecutionsParams{
+		Status:        string(filter.Status),
+		StepStatus:    string(filter.StepStatus),
+		MaxExecutions: int32(filter.Limit),
+	}
+	if filter.SagaID != nil {
+		params.FilterSagaID = true
+		params.SagaID = *filter.SagaID
+	}
+	if filter.CreatedAfter != nil {
+		params.FilterCreatedAfter = true
+		params.CreatedAfter = *filter.CreatedAfter
+	}
+	if filter.CreatedBefore != nil {
+		params.FilterCreatedBefore = true
+		params.CreatedBefore = *filter.CreatedBefore
+	}
+	if filter.After != nil {
+		params.UseCursor = true
+		params.CursorCreatedAt = filter.After.CreatedAt
+		params.CursorID = filter.After.ID
+	}
+
+	dbExecutions, err := r.q.ListSagaExecutions(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("error listing saga executions: %w", err)
+	}
+
+	executions := make([]entities.SagaExecution, 0, len(dbExecutions))
+	for _, dbExecution := range dbExecutions {
+		executions = append(executions, toSagaExecutionEntity(SagaExecution{
+			SagaExecutionID: dbExecution.SagaExecutionID,
+			SagaID:          dbExecution.SagaID,
+			CreatedAt:       dbExecution.CreatedAt,
+			Status:          dbExecution.Status,
+			FinishedAt:      dbExecution.FinishedAt,
+		}, nil))
+	}
+
+	return executions, nil
+}
+
+func (r SagaRepository) SetSagaExecutionStatus(
+	ctx context.Context,
+	status entities.SagaExecutionStatus,
+	executionID uuid.UUID,
+) error {
+	params := SetSagaExecutionStatusParams{
+		Status:          string(status),
+		SagaExecutionID: executionID,
+	}
+	return r.q.SetSagaExecutionStatus(ctx, params)
+}
+
+func (r SagaRepository) encryptSensitiveFields(
+	ctx context.Context,
+	sagaID uuid.UUID,
+	payload []byte,
+) ([]byte, error) {
+	fields, err := r.sensitiveFields(ctx, sagaID)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedPayload, err := sagas.TransformFields(payload, fields, r.keyring.Encrypt)
+	if err != nil {
+		return nil, fmt.Errorf("error encrypting sensitive fields: %w", err)
+	}
+
+	return encryptedPayload, nil
+}
+
+// sensitiveFields returns the fields the saga schema marks as sensitive, the only ones encrypted in the payloads.
+func (r SagaRepository) sensitiveFields(ctx context.Context, sagaID uuid.UUID) ([]string, error) {
+	dbSaga, err := r.q.GetSaga(ctx, sagaID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting saga schema: %w", err)
+	}
+
+	return sagas.SensitiveFields(dbSaga.Payload)
+}
+
+// CanEncrypt tells whether there's a current key to encrypt the sensitive fields with.
+func (r SagaRepository) CanEncrypt() bool {
+	return r.keyring.CanEncrypt()
+}
+
+func (r SagaRepository) HasEncryptedValues(document []byte) (bool, error) {
+	return encryption.HasEncryptedValues(document)
+}
+
 func (r SagaRepository) GetSagaStepsExecutionByExecutionID(
 	ctx context.Context,
 	executionID uuid.UUID,
@@ -157,12 +478,9 @@ func (r SagaRepository) GetSagaStepsExecutionByExecutionID(
 
 	stepExecutions := make([]entities.StepExecution, 0, len(executions))
 	for _, execution := range executions {
-		stepExecution := entities.StepExecution{
-			StepExecutionID: execution.StepExecutionID,
-			SagaExecutionID: execution.SagaExecutionID,
-			Index:           int(execution.Index),
-			Name:            execution.Name,
-			Status:          entities.StepExecutionStatus(execution.Status),
+		stepExecution, err := r.toStepExecutionEntity(execution)
+		if err != nil {
+			return nil, err
 		}
 		stepExecutions = append(stepExecutions, stepExecution)
 	}
@@ -170,15 +488,90 @@ func (r SagaRepository) GetSagaStepsExecutionByExecutionID(
 	return stepExecutions, nil
 }
 
+func (r SagaRepository) GetSagaStepsExecutionByExecutionIDs(
+	ctx context.Context,
+	executionIDs []uuid.UUID,
+) ([]entities.StepExecution, error) {
+	executions, err := r.q.GetSagaStepsExecutionByExecutionIDs(ctx, executionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error getting steps execution info: %w", err)
+	}
+
+	stepExecutions := make([]entities.StepExecution, 0, len(executions))
+	for _, execution := range executions {
+		stepExecution, err := r.toStepExecutionEntity(execution)
+		if err != nil {
+			return nil, err
+		}
+		stepExecutions = append(stepExecutions, stepExecution)
+	}
+
+	return stepExecutions, nil
+}
+
+func (r SagaRepository) toStepExecutionEntity(dbStep StepExecution) (entities.StepExecution, error) {
+	output, err := r.keyring.DecryptDocument(dbStep.Output)
+	if err != nil {
+		return entities.StepExecution{}, fmt.Errorf("error decrypting output of step %s: %w", dbStep.Name, err)
+	}
+
+	step := entities.StepExecution{
+		StepExecutionID:     dbStep.StepExecutionID,
+		SagaExecutionID:     dbStep.SagaExecutionID,
+		Index:               int(dbStep.Index),
+		Name:                dbStep.Name,
+		Status:              entities.StepExecutionStatus(dbStep.Status),
+		InputMapping:        dbStep.InputMapping,
+		Output:              output,
+		CompensateOnFailure: dbStep.CompensateOnFailure,
+		Transport:           entities.StepTransport(dbStep.Transport),
+		TransportConfig:     dbStep.TransportConfig,
+		Failed:              dbStep.Failed,
+		Attempt:             int(dbStep.Attempt),
+	}
+	if dbStep.StartedAt.Valid {
+		step.StartedAt = &dbStep.StartedAt.Time
+	}
+	if dbStep.FinishedAt.Valid {
+		step.FinishedAt = &dbStep.FinishedAt.Time
+	}
+	if len(dbStep.Error) > 0 && string(dbStep.Error) != "null" {
+		var record stepErrorRecord
+		if err := json.Unmarshal(dbStep.Error, &record); err != nil {
+			return entities.StepExecution{}, fmt.Errorf("error reading error of step %s: %w", dbStep.Name, err)
+		}
+		step.Error = &entities.StepError{
+			Code:      record.Code,
+			Message:   record.Message,
+			Retryable: record.Retryable,
+			Details:   record.Details,
+		}
+	}
+
+	return step, nil
+}
+
+// stepErrorRecord is how the step error is kept in the `error` column
+type stepErrorRecord struct {
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	Retryable bool            `json:"retryable"`
+	Details   json.RawMessage `json:"details,omitempty"`
+}
+
 func (r SagaRepository) CreateSagaStepsExecution(
 	ctx context.Context,
 	steps []entities.StepExecution,
 ) ([]entities.StepExecution, error) {
 	args := CreateSagaStepsExecutionParams{
-		SagaExecutionIds: make([]uuid.UUID, 0, len(steps)),
-		Indexes:          make([]int32, 0, len(steps)),
-		Names:            make([]string, 0, len(steps)),
-		Statuses:         make([]string, 0, len(steps)),
+		SagaExecutionIds:     make([]uuid.UUID, 0, len(steps)),
+		Indexes:              make([]int32, 0, len(steps)),
+		Names:                make([]string, 0, len(steps)),
+		Statuses:             make([]string, 0, len(steps)),
+		InputMappings:        make([]json.RawMessage, 0, len(steps)),
+		CompensateOnFailures: make([]bool, 0, len(steps)),
+		Transports:           make([]string, 0, len(steps)),
+		TransportConfigs:     make([]json.RawMessage, 0, len(steps)),
 	}
 
 	for _, step := range steps {
@@ -186,6 +579,10 @@ func (r SagaRepository) CreateSagaStepsExecution(
 		args.Indexes = append(args.Indexes, int32(step.Index))
 		args.Names = append(args.Names, step.Name)
 		args.Statuses = append(args.Statuses, string(step.Status))
+		args.InputMappings = append(args.InputMappings, step.InputMapping)
+		args.CompensateOnFailures = append(args.CompensateOnFailures, step.CompensateOnFailure)
+		args.Transports = append(args.Transports, string(step.Transport))
+		args.TransportConfigs = append(args.TransportConfigs, step.TransportConfig)
 	}
 	savedSteps, err := r.q.CreateSagaStepsExecution(ctx, args)
 	if err != nil {
@@ -194,12 +591,9 @@ func (r SagaRepository) CreateSagaStepsExecution(
 
 	stepsExecution := make([]entities.StepExecution, 0, len(savedSteps))
 	for _, step := range savedSteps {
-		stepExecution := entities.StepExecution{
-			StepExecutionID: step.StepExecutionID,
-			SagaExecutionID: step.SagaExecutionID,
-			Index:           int(step.Index),
-			Name:            step.Name,
-			Status:          entities.StepExecutionStatus(step.Status),
+		stepExecution, err := r.toStepExecutionEntity(step)
+		if err != nil {
+			return nil, err
 		}
 		stepsExecution = append(stepsExecution, stepExecution)
 	}
@@ -215,8 +609,212 @@ func (r SagaRepository) SetSagaStepExecutionStatus(
 ) error {
 	params := SetSagaStepExecutionStatusParams{
 		Status:          string(status),
-		Index: int32(index),
+		Index:           int32(index),
 		SagaExecutionID: executionID,
 	}
 	return r.q.SetSagaStepExecutionStatus(ctx, params)
 }
+
+// SetSagaStepExecutionOutput keeps the output encrypted, the step output has no schema telling its sensitive fields.
+func (r SagaRepository) SetSagaStepExecutionOutput(
+	ctx context.Context,
+	output []byte,
+	index int,
+	executionID uuid.UUID,
+) error {
+	output, err := r.keyring.EncryptDocument(output)
+	if err != nil {
+		return fmt.Errorf("error encrypting step output: %w", err)
+	}
+
+	params := SetSagaStepExecutionOutputParams{
+		Output:          output,
+		Index:           int32(index),
+		SagaExecutionID: executionID,
+	}
+	return r.q.SetSagaStepExecutionOutput(ctx, params)
+}
+
+func (r SagaRepository) SetSagaStepExecutionError(
+	ctx context.Context,
+	stepError entities.StepError,
+	index int,
+	executionID uuid.UUID,
+) error {
+	record, err := json.Marshal(stepErrorRecord{
+		Code:      stepError.Code,
+		Message:   stepError.Message,
+		Retryable: stepError.Retryable,
+		Details:   stepError.Details,
+	})
+	if err != nil {
+		return fmt.Errorf("error marshaling step error: %w", err)
+	}
+
+	params := SetSagaStepExecutionErrorParams{
+		Error:           record,
+		Index:           int32(index),
+		SagaExecutionID: executionID,
+	}
+	return r.q.SetSagaStepExecutionError(ctx, params)
+}
+
+func (r SagaRepository) IncrementSagaStepExecutionAttempt(
+	ctx context.Context,
+	index int,
+	executionID uuid.UUID,
+) (int, error) {
+	params := IncrementSagaStepExecutionAttemptParams{
+		Index:           int32(index),
+		SagaExecutionID: executionID,
+	}
+	attempt, err := r.q.IncrementSagaStepExecutionAttempt(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, sagas.ErrStepExecutionNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return int(attempt), nil
+}
+
+func (r SagaRepository) GetExpiredSagaExecutions(
+	ctx context.Context,
+	sagaID uuid.UUID,
+	createdBefore time.Time,
+	limit int,
+) ([]entities.SagaExecution, error) {
+	params := GetExpiredSagaExecutionsParams{
+		SagaID:        sagaID,
+		CreatedBefore: createdBefore,
+		MaxExecutions: int32(limit),
+	}
+	dbExecutions, err := r.q.GetExpiredSagaExecutions(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("error getting expired saga executions: %w", err)
+	}
+
+	executions := make([]entities.SagaExecution, 0, len(dbExecutions))
+	for _, dbExecution := range dbExecutions {
+		payload, err := r.readExecutionPayload(ctx, dbExecution)
+		if err != nil {
+			return nil, err
+		}
+
+		executions = append(executions, toSagaExecutionEntity(dbExecution, payload))
+	}
+
+	return executions, nil
+}
+
+func (r SagaRepository) ArchiveSagaExecutions(
+	ctx context.Context,
+	sagaID uuid.UUID,
+	executionIDs []uuid.UUID,
+	location string,
+) error {
+	dbSaga, err := r.q.GetSaga(ctx, sagaID)
+	if err != nil {
+		return fmt.Errorf("error getting saga: %w", err)
+	}
+
+	params := ArchiveSagaExecutionsParams{
+		SagaExecutionIds: executionIDs,
+		SagaID:           sagaID,
+		Location:         location,
+	}
+	references, err := r.q.ArchiveSagaExecutions(ctx, params)
+	if err != nil {
+		return fmt.Errorf("error archiving saga executions: %w", err)
+	}
+
+	// The archive has the payloads now, the offloaded ones would be left behind without any execution pointing to them
+	for _, reference := range references {
+		if err := r.claimCheck.Discard(ctx, reference); err != nil {
+			log.Printf("error discarding the payload %s of an archived execution: %v", reference, err)
+		}
+	}
+	for _, executionID := range executionIDs {
+		prefix := payload_store.StepPayloadsPrefix(dbSaga.FormattedName, executionID)
+		if err := r.claimCheck.DiscardPrefix(ctx, prefix); err != nil {
+			log.Printf("error discarding the steps payloads of archived execution %s: %v", executionID, err)
+		}
+	}
+
+	return nil
+}
+
+func (r SagaRepository) GetArchivedExecutionLocation(ctx context.Context, executionID uuid.UUID) (string, error) {
+	archivedExecution, err := r.q.GetArchivedExecution(ctx, executionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", retention.ErrArchivedExecutionNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("error getting archived execution: %w", err)
+	}
+
+	return archivedExecution.Location, nil
+}
+
+func (r SagaRepository) GetSagaExecutionStatistics(
+	ctx context.Context,
+	sagaID uuid.UUID,
+	from, to time.Time,
+) (sagas.ExecutionStatistics, error) {
+	params := GetSagaExecutionStatisticsParams{
+		SagaID:        sagaID,
+		CreatedAfter:  from,
+		CreatedBefore: to,
+	}
+	row, err := r.q.GetSagaExecutionStatistics(ctx, params)
+	if err != nil {
+		return sagas.ExecutionStatistics{}, fmt.Errorf("error getting saga execution statistics: %w", err)
+	}
+
+	return sagas.ExecutionStatistics{
+		Total:        int(row.Total),
+		Running:      int(row.Running),
+		Compensating: int(row.Compensating),
+		Succeeded:    int(row.Succeeded),
+		Failed:       int(row.Failed),
+		Compensated:  int(row.Compensated),
+		Duration:     toDurationPercentiles(row.P50Duration, row.P95Duration, row.P99Duration),
+	}, nil
+}
+
+func (r SagaRepository) GetSagaStepStatistics(
+	ctx context.Context,
+	sagaID uuid.UUID,
+	from, to time.Time,
+) ([]sagas.StepStatistics, error) {
+	params := GetSagaStepStatisticsParams{
+		SagaID:        sagaID,
+		CreatedAfter:  from,
+		CreatedBefore: to,
+	}
+	rows, err := r.q.GetSagaStepStatistics(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("error getting saga step statistics: %w", err)
+	}
+
+	statistics := make([]sagas.StepStatistics, 0, len(rows))
+	for _, row := range rows {
+		statistics = append(statistics, sagas.StepStatistics{
+			Index:    int(row.Index),
+			Name:     row.Name,
+			Total:    int(row.Total),
+			Failed:   int(row.Failed),
+			Duration: toDurationPercentiles(row.P50Duration, row.P95Duration, row.P99Duration),
+		})
+	}
+
+	return statistics, nil
+}
+
+func toDurationPercentiles(p50, p95, p99 float64) sagas.DurationPercentiles {
+	seconds := func(value float64) time.Duration {
+		return time.Duration(value * float64(time.Second))
+	}
+	return sagas.DurationPercentiles{P50: seconds(p50), P95: seconds(p95), P99: seconds(p99)}
+}
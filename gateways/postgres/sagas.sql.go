@@ -5,24 +5,71 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+const archiveSagaExecutions = `-- name: ArchiveSagaExecutions :many
+WITH archived AS (
+    INSERT INTO archived_executions (saga_execution_id, saga_id, location)
+    SELECT unnest($1::uuid[]), $2, $3
+), deleted_steps AS (
+    DELETE FROM step_executions WHERE saga_execution_id = ANY($1::uuid[])
+)
+DELETE FROM saga_executions WHERE saga_execution_id = ANY($1::uuid[])
+RETURNING payload_reference
+`
+
+type ArchiveSagaExecutionsParams struct {
+	SagaExecutionIds []uuid.UUID `db:"saga_execution_ids"`
+	SagaID           uuid.UUID   `db:"saga_id"`
+	Location         string      `db:"location"`
+}
+
+func (q *Queries) ArchiveSagaExecutions(ctx context.Context, arg ArchiveSagaExecutionsParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, archiveSagaExecutions, arg.SagaExecutionIds, arg.SagaID, arg.Location)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var payload_reference string
+		if err := rows.Scan(&payload_reference); err != nil {
+			return nil, err
+		}
+		items = append(items, payload_reference)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createSaga = `-- name: CreateSaga :one
-INSERT INTO sagas (name, formatted_name, payload)
-VALUES ($1, $2, $3) RETURNING saga_id, name, formatted_name, payload, created_at
+INSERT INTO sagas (name, formatted_name, description, payload, retention_days)
+VALUES ($1, $2, $3, $4, $5) RETURNING saga_id, name, formatted_name, payload, created_at, retention_days, description, deprecated_at
 `
 
 type CreateSagaParams struct {
 	Name          string          `db:"name"`
 	FormattedName string          `db:"formatted_name"`
+	Description   string          `db:"description"`
 	Payload       json.RawMessage `db:"payload"`
+	RetentionDays int32           `db:"retention_days"`
 }
 
 func (q *Queries) CreateSaga(ctx context.Context, arg CreateSagaParams) (Saga, error) {
-	row := q.db.QueryRow(ctx, createSaga, arg.Name, arg.FormattedName, arg.Payload)
+	row := q.db.QueryRow(ctx, createSaga,
+		arg.Name,
+		arg.FormattedName,
+		arg.Description,
+		arg.Payload,
+		arg.RetentionDays,
+	)
 	var i Saga
 	err := row.Scan(
 		&i.SagaID,
@@ -30,49 +77,72 @@ func (q *Queries) CreateSaga(ctx context.Context, arg CreateSagaParams) (Saga, e
 		&i.FormattedName,
 		&i.Payload,
 		&i.CreatedAt,
+		&i.RetentionDays,
+		&i.Description,
+		&i.DeprecatedAt,
 	)
 	return i, err
 }
 
 const createSagaExecution = `-- name: CreateSagaExecution :one
-INSERT INTO saga_executions (saga_id, payload)
-VALUES ($1, $2) RETURNING saga_execution_id, saga_id, payload, created_at
+INSERT INTO saga_executions (saga_id, payload, payload_reference)
+VALUES ($1, $2, $3) RETURNING saga_execution_id, saga_id, payload, created_at, payload_reference, status, finished_at
 `
 
 type CreateSagaExecutionParams struct {
-	SagaID  uuid.UUID       `db:"saga_id"`
-	Payload json.RawMessage `db:"payload"`
+	SagaID           uuid.UUID       `db:"saga_id"`
+	Payload          json.RawMessage `db:"payload"`
+	PayloadReference string          `db:"payload_reference"`
 }
 
 func (q *Queries) CreateSagaExecution(ctx context.Context, arg CreateSagaExecutionParams) (SagaExecution, error) {
-	row := q.db.QueryRow(ctx, createSagaExecution, arg.SagaID, arg.Payload)
+	row := q.db.QueryRow(ctx, createSagaExecution, arg.SagaID, arg.Payload, arg.PayloadReference)
 	var i SagaExecution
 	err := row.Scan(
 		&i.SagaExecutionID,
 		&i.SagaID,
 		&i.Payload,
 		&i.CreatedAt,
+		&i.PayloadReference,
+		&i.Status,
+		&i.FinishedAt,
 	)
 	return i, err
 }
 
 const createSagaSteps = `-- name: CreateSagaSteps :many
-INSERT INTO saga_steps(saga_id, index, name)
+INSERT INTO saga_steps(saga_id, index, name, input_mapping, compensate_on_failure, transport, transport_config)
 SELECT
     unnest($1::uuid[]) AS saga_id,
     unnest($2::INTEGER[]) as index,
-    unnest($3::TEXT[]) AS name
-RETURNING step_id, saga_id, index, name
+    unnest($3::TEXT[]) AS name,
+    unnest($4::JSONB[]) AS input_mapping,
+    unnest($5::BOOLEAN[]) AS compensate_on_failure,
+    unnest($6::TEXT[]) AS transport,
+    unnest($7::JSONB[]) AS transport_config
+RETURNING step_id, saga_id, index, name, input_mapping, compensate_on_failure, transport, transport_config
 `
 
 type CreateSagaStepsParams struct {
-	SagaIds []uuid.UUID `db:"saga_ids"`
-	Indexes []int32     `db:"indexes"`
-	Names   []string    `db:"names"`
+	SagaIds              []uuid.UUID       `db:"saga_ids"`
+	Indexes              []int32           `db:"indexes"`
+	Names                []string          `db:"names"`
+	InputMappings        []json.RawMessage `db:"input_mappings"`
+	CompensateOnFailures []bool            `db:"compensate_on_failures"`
+	Transports           []string          `db:"transports"`
+	TransportConfigs     []json.RawMessage `db:"transport_configs"`
 }
 
 func (q *Queries) CreateSagaSteps(ctx context.Context, arg CreateSagaStepsParams) ([]SagaStep, error) {
-	rows, err := q.db.Query(ctx, createSagaSteps, arg.SagaIds, arg.Indexes, arg.Names)
+	rows, err := q.db.Query(ctx, createSagaSteps,
+		arg.SagaIds,
+		arg.Indexes,
+		arg.Names,
+		arg.InputMappings,
+		arg.CompensateOnFailures,
+		arg.Transports,
+		arg.TransportConfigs,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -85,6 +155,10 @@ func (q *Queries) CreateSagaSteps(ctx context.Context, arg CreateSagaStepsParams
 			&i.SagaID,
 			&i.Index,
 			&i.Name,
+			&i.InputMapping,
+			&i.CompensateOnFailure,
+			&i.Transport,
+			&i.TransportConfig,
 		); err != nil {
 			return nil, err
 		}
@@ -97,20 +171,30 @@ func (q *Queries) CreateSagaSteps(ctx context.Context, arg CreateSagaStepsParams
 }
 
 const createSagaStepsExecution = `-- name: CreateSagaStepsExecution :many
-INSERT INTO step_executions(saga_execution_id, index, name, status)
+INSERT INTO step_executions(
+    saga_execution_id, index, name, status, input_mapping, compensate_on_failure, transport, transport_config
+)
 SELECT
    unnest($1::uuid[]) AS saga_execution_id,
    unnest($2::INTEGER[]) as index,
    unnest($3::TEXT[]) AS name,
-   unnest($4::TEXT[]) as status
-RETURNING step_execution_id, saga_execution_id, index, name, status
+   unnest($4::TEXT[]) as status,
+   unnest($5::JSONB[]) AS input_mapping,
+   unnest($6::BOOLEAN[]) AS compensate_on_failure,
+   unnest($7::TEXT[]) AS transport,
+   unnest($8::JSONB[]) AS transport_config
+RETURNING step_execution_id, saga_execution_id, index, name, status, input_mapping, output, started_at, finished_at, error, compensate_on_failure, transport, transport_config, failed, attempt
 `
 
 type CreateSagaStepsExecutionParams struct {
-	SagaExecutionIds []uuid.UUID `db:"saga_execution_ids"`
-	Indexes          []int32     `db:"indexes"`
-	Names            []string    `db:"names"`
-	Statuses         []string    `db:"statuses"`
+	SagaExecutionIds     []uuid.UUID       `db:"saga_execution_ids"`
+	Indexes              []int32           `db:"indexes"`
+	Names                []string          `db:"names"`
+	Statuses             []string          `db:"statuses"`
+	InputMappings        []json.RawMessage `db:"input_mappings"`
+	CompensateOnFailures []bool            `db:"compensate_on_failures"`
+	Transports           []string          `db:"transports"`
+	TransportConfigs     []json.RawMessage `db:"transport_configs"`
 }
 
 func (q *Queries) CreateSagaStepsExecution(ctx context.Context, arg CreateSagaStepsExecutionParams) ([]StepExecution, error) {
@@ -119,6 +203,10 @@ func (q *Queries) CreateSagaStepsExecution(ctx context.Context, arg CreateSagaSt
 		arg.Indexes,
 		arg.Names,
 		arg.Statuses,
+		arg.InputMappings,
+		arg.CompensateOnFailures,
+		arg.Transports,
+		arg.TransportConfigs,
 	)
 	if err != nil {
 		return nil, err
@@ -133,6 +221,117 @@ func (q *Queries) CreateSagaStepsExecution(ctx context.Context, arg CreateSagaSt
 			&i.Index,
 			&i.Name,
 			&i.Status,
+			&i.InputMapping,
+			&i.Output,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.Error,
+			&i.CompensateOnFailure,
+			&i.Transport,
+			&i.TransportConfig,
+			&i.Failed,
+			&i.Attempt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteSagaWithoutExecutions = `-- name: DeleteSagaWithoutExecutions :execrows
+WITH deleted_steps AS (
+    DELETE FROM saga_steps
+    WHERE saga_steps.saga_id = $1
+      AND NOT EXISTS (SELECT 1 FROM saga_executions WHERE saga_executions.saga_id = $1)
+      AND NOT EXISTS (SELECT 1 FROM archived_executions WHERE archived_executions.saga_id = $1)
+)
+DELETE FROM sagas
+WHERE sagas.saga_id = $1
+  AND NOT EXISTS (SELECT 1 FROM saga_executions WHERE saga_executions.saga_id = $1)
+  AND NOT EXISTS (SELECT 1 FROM archived_executions WHERE archived_executions.saga_id = $1)
+`
+
+func (q *Queries) DeleteSagaWithoutExecutions(ctx context.Context, sagaID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSagaWithoutExecutions, sagaID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deprecateSaga = `-- name: DeprecateSaga :one
+UPDATE sagas SET deprecated_at = COALESCE(deprecated_at, CURRENT_TIMESTAMP)
+WHERE saga_id = $1 RETURNING saga_id, name, formatted_name, payload, created_at, retention_days, description, deprecated_at
+`
+
+func (q *Queries) DeprecateSaga(ctx context.Context, sagaID uuid.UUID) (Saga, error) {
+	row := q.db.QueryRow(ctx, deprecateSaga, sagaID)
+	var i Saga
+	err := row.Scan(
+		&i.SagaID,
+		&i.Name,
+		&i.FormattedName,
+		&i.Payload,
+		&i.CreatedAt,
+		&i.RetentionDays,
+		&i.Description,
+		&i.DeprecatedAt,
+	)
+	return i, err
+}
+
+const getArchivedExecution = `-- name: GetArchivedExecution :one
+SELECT saga_execution_id, saga_id, location, archived_at FROM archived_executions WHERE saga_execution_id = $1
+`
+
+func (q *Queries) GetArchivedExecution(ctx context.Context, sagaExecutionID uuid.UUID) (ArchivedExecution, error) {
+	row := q.db.QueryRow(ctx, getArchivedExecution, sagaExecutionID)
+	var i ArchivedExecution
+	err := row.Scan(
+		&i.SagaExecutionID,
+		&i.SagaID,
+		&i.Location,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const getExpiredSagaExecutions = `-- name: GetExpiredSagaExecutions :many
+SELECT saga_execution_id, saga_id, payload, created_at, payload_reference, status, finished_at FROM saga_executions se
+WHERE se.saga_id = $1
+  AND se.created_at < $2
+  AND se.status IN ('succeeded', 'failed')
+ORDER BY se.created_at, se.saga_execution_id
+LIMIT $3
+`
+
+type GetExpiredSagaExecutionsParams struct {
+	SagaID        uuid.UUID `db:"saga_id"`
+	CreatedBefore time.Time `db:"created_before"`
+	MaxExecutions int32     `db:"max_executions"`
+}
+
+func (q *Queries) GetExpiredSagaExecutions(ctx context.Context, arg GetExpiredSagaExecutionsParams) ([]SagaExecution, error) {
+	rows, err := q.db.Query(ctx, getExpiredSagaExecutions, arg.SagaID, arg.CreatedBefore, arg.MaxExecutions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SagaExecution{}
+	for rows.Next() {
+		var i SagaExecution
+		if err := rows.Scan(
+			&i.SagaExecutionID,
+			&i.SagaID,
+			&i.Payload,
+			&i.CreatedAt,
+			&i.PayloadReference,
+			&i.Status,
+			&i.FinishedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -145,7 +344,7 @@ func (q *Queries) CreateSagaStepsExecution(ctx context.Context, arg CreateSagaSt
 }
 
 const getSaga = `-- name: GetSaga :one
-SELECT saga_id, name, formatted_name, payload, created_at FROM sagas WHERE saga_id = $1
+SELECT saga_id, name, formatted_name, payload, created_at, retention_days, description, deprecated_at FROM sagas WHERE saga_id = $1
 `
 
 func (q *Queries) GetSaga(ctx context.Context, sagaID uuid.UUID) (Saga, error) {
@@ -157,12 +356,15 @@ func (q *Queries) GetSaga(ctx context.Context, sagaID uuid.UUID) (Saga, error) {
 		&i.FormattedName,
 		&i.Payload,
 		&i.CreatedAt,
+		&i.RetentionDays,
+		&i.Description,
+		&i.DeprecatedAt,
 	)
 	return i, err
 }
 
 const getSagaExecution = `-- name: GetSagaExecution :one
-SELECT saga_execution_id, saga_id, payload, created_at FROM saga_executions WHERE saga_execution_id = $1
+SELECT saga_execution_id, saga_id, payload, created_at, payload_reference, status, finished_at FROM saga_executions WHERE saga_execution_id = $1
 `
 
 func (q *Queries) GetSagaExecution(ctx context.Context, sagaExecutionID uuid.UUID) (SagaExecution, error) {
@@ -173,12 +375,15 @@ func (q *Queries) GetSagaExecution(ctx context.Context, sagaExecutionID uuid.UUI
 		&i.SagaID,
 		&i.Payload,
 		&i.CreatedAt,
+		&i.PayloadReference,
+		&i.Status,
+		&i.FinishedAt,
 	)
 	return i, err
 }
 
 const getSagaStepsBySagaID = `-- name: GetSagaStepsBySagaID :many
-SELECT step_id, saga_id, index, name FROM saga_steps WHERE saga_id = $1
+SELECT step_id, saga_id, index, name, input_mapping, compensate_on_failure, transport, transport_config FROM saga_steps WHERE saga_id = $1 ORDER BY index
 `
 
 func (q *Queries) GetSagaStepsBySagaID(ctx context.Context, sagaID uuid.UUID) ([]SagaStep, error) {
@@ -195,6 +400,43 @@ func (q *Queries) GetSagaStepsBySagaID(ctx context.Context, sagaID uuid.UUID) ([
 			&i.SagaID,
 			&i.Index,
 			&i.Name,
+			&i.InputMapping,
+			&i.CompensateOnFailure,
+			&i.Transport,
+			&i.TransportConfig,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSagaStepsBySagaIDs = `-- name: GetSagaStepsBySagaIDs :many
+SELECT step_id, saga_id, index, name, input_mapping, compensate_on_failure, transport, transport_config FROM saga_steps WHERE saga_id = ANY($1::uuid[]) ORDER BY saga_id, index
+`
+
+func (q *Queries) GetSagaStepsBySagaIDs(ctx context.Context, sagaIds []uuid.UUID) ([]SagaStep, error) {
+	rows, err := q.db.Query(ctx, getSagaStepsBySagaIDs, sagaIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SagaStep{}
+	for rows.Next() {
+		var i SagaStep
+		if err := rows.Scan(
+			&i.StepID,
+			&i.SagaID,
+			&i.Index,
+			&i.Name,
+			&i.InputMapping,
+			&i.CompensateOnFailure,
+			&i.Transport,
+			&i.TransportConfig,
 		); err != nil {
 			return nil, err
 		}
@@ -207,7 +449,7 @@ func (q *Queries) GetSagaStepsBySagaID(ctx context.Context, sagaID uuid.UUID) ([
 }
 
 const getSagaStepsExecutionByExecutionID = `-- name: GetSagaStepsExecutionByExecutionID :many
-SELECT step_execution_id, saga_execution_id, index, name, status FROM step_executions WHERE saga_execution_id = $1 ORDER BY index
+SELECT step_execution_id, saga_execution_id, index, name, status, input_mapping, output, started_at, finished_at, error, compensate_on_failure, transport, transport_config, failed, attempt FROM step_executions WHERE saga_execution_id = $1 ORDER BY index
 `
 
 func (q *Queries) GetSagaStepsExecutionByExecutionID(ctx context.Context, sagaExecutionID uuid.UUID) ([]StepExecution, error) {
@@ -225,6 +467,16 @@ func (q *Queries) GetSagaStepsExecutionByExecutionID(ctx context.Context, sagaEx
 			&i.Index,
 			&i.Name,
 			&i.Status,
+			&i.InputMapping,
+			&i.Output,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.Error,
+			&i.CompensateOnFailure,
+			&i.Transport,
+			&i.TransportConfig,
+			&i.Failed,
+			&i.Attempt,
 		); err != nil {
 			return nil, err
 		}
@@ -236,8 +488,310 @@ func (q *Queries) GetSagaStepsExecutionByExecutionID(ctx context.Context, sagaEx
 	return items, nil
 }
 
+const getSagaStepsExecutionByExecutionIDs = `-- name: GetSagaStepsExecutionByExecutionIDs :many
+SELECT step_execution_id, saga_execution_id, index, name, status, input_mapping, output, started_at, finished_at, error, compensate_on_failure, transport, transport_config, failed, attempt FROM step_executions
+WHERE saga_execution_id = ANY($1::uuid[])
+ORDER BY saga_execution_id, index
+`
+
+func (q *Queries) GetSagaStepsExecutionByExecutionIDs(ctx context.Context, sagaExecutionIds []uuid.UUID) ([]StepExecution, error) {
+	rows, err := q.db.Query(ctx, getSagaStepsExecutionByExecutionIDs, sagaExecutionIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []StepExecution{}
+	for rows.Next() {
+		var i StepExecution
+		if err := rows.Scan(
+			&i.StepExecutionID,
+			&i.SagaExecutionID,
+			&i.Index,
+			&i.Name,
+			&i.Status,
+			&i.InputMapping,
+			&i.Output,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.Error,
+			&i.CompensateOnFailure,
+			&i.Transport,
+			&i.TransportConfig,
+			&i.Failed,
+			&i.Attempt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSagasWithRetention = `-- name: GetSagasWithRetention :many
+SELECT saga_id, name, formatted_name, payload, created_at, retention_days, description, deprecated_at FROM sagas WHERE retention_days > 0
+`
+
+func (q *Queries) GetSagasWithRetention(ctx context.Context) ([]Saga, error) {
+	rows, err := q.db.Query(ctx, getSagasWithRetention)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Saga{}
+	for rows.Next() {
+		var i Saga
+		if err := rows.Scan(
+			&i.SagaID,
+			&i.Name,
+			&i.FormattedName,
+			&i.Payload,
+			&i.CreatedAt,
+			&i.RetentionDays,
+			&i.Description,
+			&i.DeprecatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const incrementSagaStepExecutionAttempt = `-- name: IncrementSagaStepExecutionAttempt :one
+UPDATE step_executions SET attempt = attempt + 1
+WHERE index = $1 AND saga_execution_id = $2
+RETURNING attempt
+`
+
+type IncrementSagaStepExecutionAttemptParams struct {
+	Index           int32     `db:"index"`
+	SagaExecutionID uuid.UUID `db:"saga_execution_id"`
+}
+
+func (q *Queries) IncrementSagaStepExecutionAttempt(ctx context.Context, arg IncrementSagaStepExecutionAttemptParams) (int32, error) {
+	row := q.db.QueryRow(ctx, incrementSagaStepExecutionAttempt, arg.Index, arg.SagaExecutionID)
+	var attempt int32
+	err := row.Scan(&attempt)
+	return attempt, err
+}
+
+const listSagaExecutions = `-- name: ListSagaExecutions :many
+SELECT se.saga_execution_id, se.saga_id, se.created_at, se.status, se.finished_at FROM saga_executions se
+WHERE (NOT $1::BOOLEAN OR se.saga_id = $2::uuid)
+  AND ($3::TEXT = '' OR se.status = $3::TEXT)
+  AND ($4::TEXT = '' OR EXISTS (
+    SELECT 1 FROM step_executions ste
+    WHERE ste.saga_execution_id = se.saga_execution_id
+      -- Failed steps are found even after they compensated themselves
+      AND CASE WHEN $4::TEXT = 'error' THEN ste.failed ELSE ste.status = $4::TEXT END
+  ))
+  AND (NOT $5::BOOLEAN OR se.created_at >= $6::TIMESTAMP)
+  AND (NOT $7::BOOLEAN OR se.created_at < $8::TIMESTAMP)
+  AND (NOT $9::BOOLEAN OR (se.created_at, se.saga_execution_id) < ($10::TIMESTAMP, $11::uuid))
+ORDER BY se.created_at DESC, se.saga_execution_id DESC
+LIMIT $12
+`
+
+type ListSagaExecutionsParams struct {
+	FilterSagaID        bool      `db:"filter_saga_id"`
+	SagaID              uuid.UUID `db:"saga_id"`
+	Status              string    `db:"status"`
+	StepStatus          string    `db:"step_status"`
+	FilterCreatedAfter  bool      `db:"filter_created_after"`
+	CreatedAfter        time.Time `db:"created_after"`
+	FilterCreatedBefore bool      `db:"filter_created_before"`
+	CreatedBefore       time.Time `db:"created_before"`
+	UseCursor           bool      `db:"use_cursor"`
+	CursorCreatedAt     time.Time `db:"cursor_created_at"`
+	CursorID            uuid.UUID `db:"cursor_id"`
+	MaxExecutions       int32     `db:"max_executions"`
+}
+
+type ListSagaExecutionsRow struct {
+	SagaExecutionID uuid.UUID    `db:"saga_execution_id"`
+	SagaID          uuid.UUID    `db:"saga_id"`
+	CreatedAt       time.Time    `db:"created_at"`
+	Status          string       `db:"status"`
+	FinishedAt      sql.NullTime `db:"finished_at"`
+}
+
+func (q *Queries) ListSagaExecutions(ctx context.Context, arg ListSagaExecutionsParams) ([]ListSagaExecutionsRow, error) {
+	rows, err := q.db.Query(ctx, listSagaExecutions,
+		arg.FilterSagaID,
+		arg.SagaID,
+		arg.Status,
+		arg.StepStatus,
+		arg.FilterCreatedAfter,
+		arg.CreatedAfter,
+		arg.FilterCreatedBefore,
+		arg.CreatedBefore,
+		arg.UseCursor,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.MaxExecutions,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSagaExecutionsRow{}
+	for rows.Next() {
+		var i ListSagaExecutionsRow
+		if err := rows.Scan(
+			&i.SagaExecutionID,
+			&i.SagaID,
+			&i.CreatedAt,
+			&i.Status,
+			&i.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSagas = `-- name: ListSagas :many
+SELECT saga_id, name, formatted_name, created_at, retention_days, description, deprecated_at FROM sagas
+WHERE ($1::TEXT = '' OR strpos(lower(name), lower($1::TEXT)) > 0)
+  AND ($2::TEXT = '' OR formatted_name = $2::TEXT)
+  AND (NOT $3::BOOLEAN OR created_at >= $4::TIMESTAMP)
+  AND (NOT $5::BOOLEAN OR created_at < $6::TIMESTAMP)
+  AND (NOT $7::BOOLEAN OR (deprecated_at IS NOT NULL) = $8::BOOLEAN)
+  AND (NOT $9::BOOLEAN OR (created_at, saga_id) < ($10::TIMESTAMP, $11::uuid))
+ORDER BY created_at DESC, saga_id DESC
+LIMIT $12
+`
+
+type ListSagasParams struct {
+	Name                string    `db:"name"`
+	FormattedName       string    `db:"formatted_name"`
+	FilterCreatedAfter  bool      `db:"filter_created_after"`
+	CreatedAfter        time.Time `db:"created_after"`
+	FilterCreatedBefore bool      `db:"filter_created_before"`
+	CreatedBefore       time.Time `db:"created_before"`
+	FilterDeprecated    bool      `db:"filter_deprecated"`
+	Deprecated          bool      `db:"deprecated"`
+	UseCursor           bool      `db:"use_cursor"`
+	CursorCreatedAt     time.Time `db:"cursor_created_at"`
+	CursorID            uuid.UUID `db:"cursor_id"`
+	MaxSagas            int32     `db:"max_sagas"`
+}
+
+type ListSagasRow struct {
+	SagaID        uuid.UUID    `db:"saga_id"`
+	Name          string       `db:"name"`
+	FormattedName string       `db:"formatted_name"`
+	CreatedAt     time.Time    `db:"created_at"`
+	RetentionDays int32        `db:"retention_days"`
+	Description   string       `db:"description"`
+	DeprecatedAt  sql.NullTime `db:"deprecated_at"`
+}
+
+func (q *Queries) ListSagas(ctx context.Context, arg ListSagasParams) ([]ListSagasRow, error) {
+	rows, err := q.db.Query(ctx, listSagas,
+		arg.Name,
+		arg.FormattedName,
+		arg.FilterCreatedAfter,
+		arg.CreatedAfter,
+		arg.FilterCreatedBefore,
+		arg.CreatedBefore,
+		arg.FilterDeprecated,
+		arg.Deprecated,
+		arg.UseCursor,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.MaxSagas,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSagasRow{}
+	for rows.Next() {
+		var i ListSagasRow
+		if err := rows.Scan(
+			&i.SagaID,
+			&i.Name,
+			&i.FormattedName,
+			&i.CreatedAt,
+			&i.RetentionDays,
+			&i.Description,
+			&i.DeprecatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setSagaExecutionStatus = `-- name: SetSagaExecutionStatus :exec
+UPDATE saga_executions
+SET status = $1::TEXT,
+    finished_at = CASE WHEN $1::TEXT IN ('succeeded', 'failed') THEN CURRENT_TIMESTAMP ELSE finished_at END
+WHERE saga_execution_id = $2
+`
+
+type SetSagaExecutionStatusParams struct {
+	Status          string    `db:"status"`
+	SagaExecutionID uuid.UUID `db:"saga_execution_id"`
+}
+
+func (q *Queries) SetSagaExecutionStatus(ctx context.Context, arg SetSagaExecutionStatusParams) error {
+	_, err := q.db.Exec(ctx, setSagaExecutionStatus, arg.Status, arg.SagaExecutionID)
+	return err
+}
+
+const setSagaStepExecutionError = `-- name: SetSagaStepExecutionError :exec
+UPDATE step_executions SET error = $1 WHERE index = $2 AND saga_execution_id = $3
+`
+
+type SetSagaStepExecutionErrorParams struct {
+	Error           json.RawMessage `db:"error"`
+	Index           int32           `db:"index"`
+	SagaExecutionID uuid.UUID       `db:"saga_execution_id"`
+}
+
+func (q *Queries) SetSagaStepExecutionError(ctx context.Context, arg SetSagaStepExecutionErrorParams) error {
+	_, err := q.db.Exec(ctx, setSagaStepExecutionError, arg.Error, arg.Index, arg.SagaExecutionID)
+	return err
+}
+
+const setSagaStepExecutionOutput = `-- name: SetSagaStepExecutionOutput :exec
+UPDATE step_executions SET output = $1 WHERE index = $2 AND saga_execution_id = $3
+`
+
+type SetSagaStepExecutionOutputParams struct {
+	Output          json.RawMessage `db:"output"`
+	Index           int32           `db:"index"`
+	SagaExecutionID uuid.UUID       `db:"saga_execution_id"`
+}
+
+func (q *Queries) SetSagaStepExecutionOutput(ctx context.Context, arg SetSagaStepExecutionOutputParams) error {
+	_, err := q.db.Exec(ctx, setSagaStepExecutionOutput, arg.Output, arg.Index, arg.SagaExecutionID)
+	return err
+}
+
 const setSagaStepExecutionStatus = `-- name: SetSagaStepExecutionStatus :exec
-UPDATE step_executions SET status = $1 WHERE index = $2 AND saga_execution_id = $3
+UPDATE step_executions
+SET status = $1::TEXT,
+    started_at = CASE WHEN $1::TEXT = 'started' THEN CURRENT_TIMESTAMP ELSE started_at END,
+    finished_at = CASE WHEN $1::TEXT IN ('finished', 'error') THEN CURRENT_TIMESTAMP ELSE finished_at END,
+    failed = failed OR $1::TEXT = 'error'
+WHERE index = $2 AND saga_execution_id = $3
 `
 
 type SetSagaStepExecutionStatusParams struct {
@@ -250,3 +804,36 @@ func (q *Queries) SetSagaStepExecutionStatus(ctx context.Context, arg SetSagaSte
 	_, err := q.db.Exec(ctx, setSagaStepExecutionStatus, arg.Status, arg.Index, arg.SagaExecutionID)
 	return err
 }
+
+const updateSaga = `-- name: UpdateSaga :one
+UPDATE sagas SET name = $2, description = $3, retention_days = $4
+WHERE saga_id = $1 RETURNING saga_id, name, formatted_name, payload, created_at, retention_days, description, deprecated_at
+`
+
+type UpdateSagaParams struct {
+	SagaID        uuid.UUID `db:"saga_id"`
+	Name          string    `db:"name"`
+	Description   string    `db:"description"`
+	RetentionDays int32     `db:"retention_days"`
+}
+
+func (q *Queries) UpdateSaga(ctx context.Context, arg UpdateSagaParams) (Saga, error) {
+	row := q.db.QueryRow(ctx, updateSaga,
+		arg.SagaID,
+		arg.Name,
+		arg.Description,
+		arg.RetentionDays,
+	)
+	var i Saga
+	err := row.Scan(
+		&i.SagaID,
+		&i.Name,
+		&i.FormattedName,
+		&i.Payload,
+		&i.CreatedAt,
+		&i.RetentionDays,
+		&i.Description,
+		&i.DeprecatedAt,
+	)
+	return i, err
+}
@@ -2,38 +2,149 @@
 SELECT * FROM sagas WHERE saga_id = $1;
 
 -- name: CreateSaga :one
-INSERT INTO sagas (name, formatted_name, payload)
-VALUES ($1, $2, $3) RETURNING *;
+INSERT INTO sagas (name, formatted_name, description, payload, retention_days)
+VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: UpdateSaga :one
+UPDATE sagas SET name = $2, description = $3, retention_days = $4
+WHERE saga_id = $1 RETURNING *;
+
+-- name: DeprecateSaga :one
+UPDATE sagas SET deprecated_at = COALESCE(deprecated_at, CURRENT_TIMESTAMP)
+WHERE saga_id = $1 RETURNING *;
+
+-- name: DeleteSagaWithoutExecutions :execrows
+WITH deleted_steps AS (
+    DELETE FROM saga_steps
+    WHERE saga_steps.saga_id = @saga_id
+      AND NOT EXISTS (SELECT 1 FROM saga_executions WHERE saga_executions.saga_id = @saga_id)
+      AND NOT EXISTS (SELECT 1 FROM archived_executions WHERE archived_executions.saga_id = @saga_id)
+)
+DELETE FROM sagas
+WHERE sagas.saga_id = @saga_id
+  AND NOT EXISTS (SELECT 1 FROM saga_executions WHERE saga_executions.saga_id = @saga_id)
+  AND NOT EXISTS (SELECT 1 FROM archived_executions WHERE archived_executions.saga_id = @saga_id);
+
+-- name: ListSagas :many
+SELECT saga_id, name, formatted_name, created_at, retention_days, description, deprecated_at FROM sagas
+WHERE (@name::TEXT = '' OR strpos(lower(name), lower(@name::TEXT)) > 0)
+  AND (@formatted_name::TEXT = '' OR formatted_name = @formatted_name::TEXT)
+  AND (NOT @filter_created_after::BOOLEAN OR created_at >= @created_after::TIMESTAMP)
+  AND (NOT @filter_created_before::BOOLEAN OR created_at < @created_before::TIMESTAMP)
+  AND (NOT @filter_deprecated::BOOLEAN OR (deprecated_at IS NOT NULL) = @deprecated::BOOLEAN)
+  AND (NOT @use_cursor::BOOLEAN OR (created_at, saga_id) < (@cursor_created_at::TIMESTAMP, @cursor_id::uuid))
+ORDER BY created_at DESC, saga_id DESC
+LIMIT @max_sagas;
 
 -- name: GetSagaStepsBySagaID :many
-SELECT * FROM saga_steps WHERE saga_id = $1;
+SELECT * FROM saga_steps WHERE saga_id = $1 ORDER BY index;
+
+-- name: GetSagaStepsBySagaIDs :many
+SELECT * FROM saga_steps WHERE saga_id = ANY(@saga_ids::uuid[]) ORDER BY saga_id, index;
 
 -- name: CreateSagaSteps :many
-INSERT INTO saga_steps(saga_id, index, name)
+INSERT INTO saga_steps(saga_id, index, name, input_mapping, compensate_on_failure, transport, transport_config)
 SELECT
     unnest(@saga_ids::uuid[]) AS saga_id,
     unnest(@indexes::INTEGER[]) as index,
-    unnest(@names::TEXT[]) AS name
+    unnest(@names::TEXT[]) AS name,
+    unnest(@input_mappings::JSONB[]) AS input_mapping,
+    unnest(@compensate_on_failures::BOOLEAN[]) AS compensate_on_failure,
+    unnest(@transports::TEXT[]) AS transport,
+    unnest(@transport_configs::JSONB[]) AS transport_config
 RETURNING *;
 
 -- name: GetSagaExecution :one
 SELECT * FROM saga_executions WHERE saga_execution_id = $1;
 
+-- name: ListSagaExecutions :many
+SELECT se.saga_execution_id, se.saga_id, se.created_at, se.status, se.finished_at FROM saga_executions se
+WHERE (NOT @filter_saga_id::BOOLEAN OR se.saga_id = @saga_id::uuid)
+  AND (@status::TEXT = '' OR se.status = @status::TEXT)
+  AND (@step_status::TEXT = '' OR EXISTS (
+    SELECT 1 FROM step_executions ste
+    WHERE ste.saga_execution_id = se.saga_execution_id
+      -- Failed steps are found even after they compensated themselves
+      AND CASE WHEN @step_status::TEXT = 'error' THEN ste.failed ELSE ste.status = @step_status::TEXT END
+  ))
+  AND (NOT @filter_created_after::BOOLEAN OR se.created_at >= @created_after::TIMESTAMP)
+  AND (NOT @filter_created_before::BOOLEAN OR se.created_at < @created_before::TIMESTAMP)
+  AND (NOT @use_cursor::BOOLEAN OR (se.created_at, se.saga_execution_id) < (@cursor_created_at::TIMESTAMP, @cursor_id::uuid))
+ORDER BY se.created_at DESC, se.saga_execution_id DESC
+LIMIT @max_executions;
+
+-- name: SetSagaExecutionStatus :exec
+UPDATE saga_executions
+SET status = @status::TEXT,
+    finished_at = CASE WHEN @status::TEXT IN ('succeeded', 'failed') THEN CURRENT_TIMESTAMP ELSE finished_at END
+WHERE saga_execution_id = @saga_execution_id;
+
 -- name: CreateSagaExecution :one
-INSERT INTO saga_executions (saga_id, payload)
-VALUES ($1, $2) RETURNING *;
+INSERT INTO saga_executions (saga_id, payload, payload_reference)
+VALUES ($1, $2, $3) RETURNING *;
 
 -- name: GetSagaStepsExecutionByExecutionID :many
 SELECT * FROM step_executions WHERE saga_execution_id = $1 ORDER BY index;
 
+-- name: GetSagaStepsExecutionByExecutionIDs :many
+SELECT * FROM step_executions
+WHERE saga_execution_id = ANY(@saga_execution_ids::uuid[])
+ORDER BY saga_execution_id, index;
+
 -- name: CreateSagaStepsExecution :many
-INSERT INTO step_executions(saga_execution_id, index, name, status)
+INSERT INTO step_executions(
+    saga_execution_id, index, name, status, input_mapping, compensate_on_failure, transport, transport_config
+)
 SELECT
    unnest(@saga_execution_ids::uuid[]) AS saga_execution_id,
    unnest(@indexes::INTEGER[]) as index,
    unnest(@names::TEXT[]) AS name,
-   unnest(@statuses::TEXT[]) as status
+   unnest(@statuses::TEXT[]) as status,
+   unnest(@input_mappings::JSONB[]) AS input_mapping,
+   unnest(@compensate_on_failures::BOOLEAN[]) AS compensate_on_failure,
+   unnest(@transports::TEXT[]) AS transport,
+   unnest(@transport_configs::JSONB[]) AS transport_config
 RETURNING *;
 
 -- name: SetSagaStepExecutionStatus :exec
-UPDATE step_executions SET status = $1 WHERE index = $2 AND saga_execution_id = $3;
+UPDATE step_executions
+SET status = @status::TEXT,
+    started_at = CASE WHEN @status::TEXT = 'started' THEN CURRENT_TIMESTAMP ELSE started_at END,
+    finished_at = CASE WHEN @status::TEXT IN ('finished', 'error') THEN CURRENT_TIMESTAMP ELSE finished_at END,
+    failed = failed OR @status::TEXT = 'error'
+WHERE index = @index AND saga_execution_id = @saga_execution_id;
+
+-- name: SetSagaStepExecutionOutput :exec
+UPDATE step_executions SET output = $1 WHERE index = $2 AND saga_execution_id = $3;
+
+-- name: SetSagaStepExecutionError :exec
+UPDATE step_executions SET error = $1 WHERE index = $2 AND saga_execution_id = $3;
+
+-- name: IncrementSagaStepExecutionAttempt :one
+UPDATE step_executions SET attempt = attempt + 1
+WHERE index = @index AND saga_execution_id = @saga_execution_id
+RETURNING attempt;
+
+-- name: GetSagasWithRetention :many
+SELECT * FROM sagas WHERE retention_days > 0;
+
+-- name: GetExpiredSagaExecutions :many
+SELECT * FROM saga_executions se
+WHERE se.saga_id = @saga_id
+  AND se.created_at < @created_before
+  AND se.status IN ('succeeded', 'failed')
+ORDER BY se.created_at, se.saga_execution_id
+LIMIT @max_executions;
+
+-- name: ArchiveSagaExecutions :many
+WITH archived AS (
+    INSERT INTO archived_executions (saga_execution_id, saga_id, location)
+    SELECT unnest(@saga_execution_ids::uuid[]), @saga_id, @location
+), deleted_steps AS (
+    DELETE FROM step_executions WHERE saga_execution_id = ANY(@saga_execution_ids::uuid[])
+)
+DELETE FROM saga_executions WHERE saga_execution_id = ANY(@saga_execution_ids::uuid[])
+RETURNING payload_reference;
+
+-- name: GetArchivedExecution :one
+SELECT * FROM archived_executions WHERE saga_execution_id = $1;
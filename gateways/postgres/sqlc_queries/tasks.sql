@@ -0,0 +1,37 @@
+-- name: CreateTask :exec
+INSERT INTO tasks (queue, saga_name, saga_execution_id, step_index, step)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: LeaseTask :one
+UPDATE tasks
+SET token = @token,
+    leased_until = CURRENT_TIMESTAMP + make_interval(secs => @lease_seconds::INTEGER)
+WHERE task_id = (
+    SELECT t.task_id FROM tasks t
+    WHERE t.queue = ANY(@queues::TEXT[])
+      AND (t.leased_until IS NULL OR t.leased_until < CURRENT_TIMESTAMP)
+    ORDER BY t.created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ClaimTask :one
+UPDATE tasks
+SET token = @claim_token,
+    leased_until = GREATEST(leased_until, CURRENT_TIMESTAMP + make_interval(secs => @lease_seconds::INTEGER))
+WHERE token = @token
+  AND leased_until >= CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: ReleaseTask :exec
+UPDATE tasks SET token = @token WHERE token = @claim_token;
+
+-- name: ExtendTaskLease :one
+UPDATE tasks
+SET leased_until = CURRENT_TIMESTAMP + make_interval(secs => @lease_seconds::INTEGER)
+WHERE token = @token
+RETURNING *;
+
+-- name: DeleteTask :exec
+DELETE FROM tasks WHERE task_id = $1;
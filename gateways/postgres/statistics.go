@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The statistics queries are written by hand because sqlc can't type ordered-set aggregates like percentile_cont.
+
+const getSagaExecutionStatistics = `
+SELECT
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE se.status = 'running') AS running,
+    COUNT(*) FILTER (WHERE se.status = 'compensating') AS compensating,
+    COUNT(*) FILTER (WHERE se.status = 'succeeded') AS succeeded,
+    COUNT(*) FILTER (WHERE se.status = 'failed') AS failed,
+    COUNT(*) FILTER (WHERE EXISTS (
+        SELECT 1 FROM step_executions ste
+        WHERE ste.saga_execution_id = se.saga_execution_id AND ste.status = 'compensated'
+    )) AS compensated,
+    COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM se.finished_at - se.created_at)), 0) AS p50_duration,
+    COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM se.finished_at - se.created_at)), 0) AS p95_duration,
+    COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM se.finished_at - se.created_at)), 0) AS p99_duration
+FROM saga_executions se
+WHERE se.saga_id = $1
+  AND se.created_at >= $2
+  AND se.created_at < $3
+`
+
+type GetSagaExecutionStatisticsParams struct {
+	SagaID        uuid.UUID `db:"saga_id"`
+	CreatedAfter  time.Time `db:"created_after"`
+	CreatedBefore time.Time `db:"created_before"`
+}
+
+// GetSagaExecutionStatisticsRow has the durations in seconds
+type GetSagaExecutionStatisticsRow struct {
+	Total        int64   `db:"total"`
+	Running      int64   `db:"running"`
+	Compensating int64   `db:"compensating"`
+	Succeeded    int64   `db:"succeeded"`
+	Failed       int64   `db:"failed"`
+	Compensated  int64   `db:"compensated"`
+	P50Duration  float64 `db:"p50_duration"`
+	P95Duration  float64 `db:"p95_duration"`
+	P99Duration  float64 `db:"p99_duration"`
+}
+
+func (q *Queries) GetSagaExecutionStatistics(
+	ctx context.Context,
+	arg GetSagaExecutionStatisticsParams,
+) (GetSagaExecutionStatisticsRow, error) {
+	row := q.db.QueryRow(ctx, getSagaExecutionStatistics, arg.SagaID, arg.CreatedAfter, arg.CreatedBefore)
+	var i GetSagaExecutionStatisticsRow
+	err := row.Scan(
+		&i.Total,
+		&i.Running,
+		&i.Compensating,
+		&i.Succeeded,
+		&i.Failed,
+		&i.Compensated,
+		&i.P50Duration,
+		&i.P95Duration,
+		&i.P99Duration,
+	)
+	return i, err
+}
+
+const getSagaStepStatistics = `
+SELECT
+    ste.index,
+    ste.name,
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE ste.failed) AS failed,
+    COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM ste.finished_at - ste.started_at)), 0) AS p50_duration,
+    COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM ste.finished_at - ste.started_at)), 0) AS p95_duration,
+    COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM ste.finished_at - ste.started_at)), 0) AS p99_duration
+FROM step_executions ste
+JOIN saga_executions se ON se.saga_execution_id = ste.saga_execution_id
+WHERE se.saga_id = $1
+  AND se.created_at >= $2
+  AND se.created_at < $3
+  AND ste.started_at IS NOT NULL
+GROUP BY ste.index, ste.name
+ORDER BY ste.index
+`
+
+type GetSagaStepStatisticsParams struct {
+	SagaID        uuid.UUID `db:"saga_id"`
+	CreatedAfter  time.Time `db:"created_after"`
+	CreatedBefore time.Time `db:"created_before"`
+}
+
+// GetSagaStepStatisticsRow has the durations in seconds
+type GetSagaStepStatisticsRow struct {
+	Index       int32   `db:"index"`
+	Name        string  `db:"name"`
+	Total       int64   `db:"total"`
+	Failed      int64   `db:"failed"`
+	P50Duration float64 `db:"p50_duration"`
+	P95Duration float64 `db:"p95_duration"`
+	P99Duration float64 `db:"p99_duration"`
+}
+
+func (q *Queries) GetSagaStepStatistics(
+	ctx context.Context,
+	arg GetSagaStepStatisticsParams,
+) ([]GetSagaStepStatisticsRow, error) {
+	rows, err := q.db.Query(ctx, getSagaStepStatistics, arg.SagaID, arg.CreatedAfter, arg.CreatedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSagaStepStatisticsRow{}
+	for rows.Next() {
+		var i GetSagaStepStatisticsRow
+		if err := rows.Scan(
+			&i.Index,
+			&i.Name,
+			&i.Total,
+			&i.Failed,
+			&i.P50Duration,
+			&i.P95Duration,
+			&i.P99Duration,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/tasks"
+	"github.com/thepabloaguilar/sukuna/gateways/encryption"
+)
+
+type TaskRepository struct {
+	q       Queries
+	keyring encryption.Keyring
+}
+
+// NewTaskRepository keeps the steps encrypted with the keyring, they carry the sensitive fields of the payload.
+func NewTaskRepository(q Queries, keyring encryption.Keyring) TaskRepository {
+	return TaskRepository{q: q, keyring: keyring}
+}
+
+func (r TaskRepository) CreateTask(ctx context.Context, task entities.Task) error {
+	step, err := r.keyring.EncryptDocument(task.Step)
+	if err != nil {
+		return fmt.Errorf("error encrypting task step: %w", err)
+	}
+
+	err = r.q.CreateTask(ctx, CreateTaskParams{
+		Queue:           task.Queue,
+		SagaName:        task.SagaName,
+		SagaExecutionID: task.SagaExecutionID,
+		StepIndex:       int32(task.StepIndex),
+		Step:            step,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating task: %w", err)
+	}
+
+	return nil
+}
+
+func (r TaskRepository) LeaseTask(
+	ctx context.Context,
+	queues []string,
+	token uuid.UUID,
+	lease time.Duration,
+) (entities.Task, error) {
+	dbTask, err := r.q.LeaseTask(ctx, LeaseTaskParams{
+		Token:        token,
+		LeaseSeconds: int32(lease / time.Second),
+		Queues:       queues,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Task{}, tasks.ErrNoTask
+	}
+	if err != nil {
+		return entities.Task{}, fmt.Errorf("error leasing task: %w", err)
+	}
+
+	return r.toTaskEntity(dbTask)
+}
+
+// ClaimTask moves the task from the token to the claim token, only while the lease of the token is valid,
+// so a task is claimed once. The lease is extended to last at least the given duration.
+func (r TaskRepository) ClaimTask(
+	ctx context.Context,
+	token uuid.UUID,
+	claimToken uuid.UUID,
+	lease time.Duration,
+) (entities.Task, error) {
+	dbTask, err := r.q.ClaimTask(ctx, ClaimTaskParams{
+		ClaimToken:   claimToken,
+		LeaseSeconds: int32(lease / time.Second),
+		Token:        token,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Task{}, tasks.ErrTaskNotFound
+	}
+	if err != nil {
+		return entities.Task{}, fmt.Errorf("error claiming task: %w", err)
+	}
+
+	return r.toTaskEntity(dbTask)
+}
+
+func (r TaskRepository) ExtendTaskLease(
+	ctx context.Context,
+	token uuid.UUID,
+	lease time.Duration,
+) (entities.Task, error) {
+	dbTask, err := r.q.ExtendTaskLease(ctx, ExtendTaskLeaseParams{
+		LeaseSeconds: int32(lease / time.Second),
+		Token:        token,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Task{}, tasks.ErrTaskNotFound
+	}
+	if err != nil {
+		return entities.Task{}, fmt.Errorf("error extending task lease: %w", err)
+	}
+
+	return r.toTaskEntity(dbTask)
+}
+
+func (r TaskRepository) ReleaseTask(ctx context.Context, claimToken uuid.UUID, token uuid.UUID) error {
+	err := r.q.ReleaseTask(ctx, ReleaseTaskParams{Token: token, ClaimToken: claimToken})
+	if err != nil {
+		return fmt.Errorf("error releasing task: %w", err)
+	}
+
+	return nil
+}
+
+func (r TaskRepository) DeleteTask(ctx context.Context, taskID uuid.UUID) error {
+	return r.q.DeleteTask(ctx, taskID)
+}
+
+func (r TaskRepository) toTaskEntity(dbTask Task) (entities.Task, error) {
+	step, err := r.keyring.DecryptDocument(dbTask.Step)
+	if err != nil {
+		return entities.Task{}, fmt.Errorf("error decrypting task step: %w", err)
+	}
+
+	task := entities.Task{
+		TaskID:          dbTask.TaskID,
+		Token:           dbTask.Token,
+		Queue:           dbTask.Queue,
+		SagaName:        dbTask.SagaName,
+		SagaExecutionID: dbTask.SagaExecutionID,
+		StepIndex:       int(dbTask.StepIndex),
+		Step:            step,
+		CreatedAt:       dbTask.CreatedAt,
+	}
+	if dbTask.LeasedUntil.Valid {
+		task.LeasedUntil = &dbTask.LeasedUntil.Time
+	}
+
+	return task, nil
+}
@@ -0,0 +1,194 @@
+package step_execution
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/gateways/payload_store"
+)
+
+const (
+	// AMQPResultExchange and AMQPResultQueue are where the workers publish the step results,
+	// using AMQPResultRoutingKey.
+	AMQPResultExchange   = "sukuna"
+	AMQPResultQueue      = "sukuna-out"
+	AMQPResultRoutingKey = "results"
+
+	amqpConfirmTimeout = 10 * time.Second
+)
+
+var ErrStepNotConfirmed = errors.New("step was not confirmed by the broker")
+
+// AMQPStepExchange is the direct exchange of a saga, steps are routed by their names.
+func AMQPStepExchange(sagaName string) string {
+	return fmt.Sprintf("sukuna.%s", sagaName)
+}
+
+// AMQPStepQueue is the queue the workers of a step consume from, it has the same name as the Kafka topic.
+func AMQPStepQueue(sagaName, stepName string) string {
+	return fmt.Sprintf("%s-%s", sagaName, stepName)
+}
+
+type amqpGateway struct {
+	mutex      sync.Mutex
+	url        string
+	connection *amqp.Connection
+	channel    *amqp.Channel
+	confirms   <-chan amqp.Confirmation
+	published  uint64
+	declared   map[string]bool
+	claimCheck payload_store.ClaimCheck
+}
+
+// NewAMQPGateway publishes the steps with publisher confirms, a step is only sent once the broker confirmed it.
+// The exchange and the queue of every step are declared the first time the step is sent. The channel is dropped
+// whenever a publish fails or isn't confirmed in time, the next step reconnects to the broker at url.
+func NewAMQPGateway(url string, claimCheck payload_store.ClaimCheck) (sagas.StepExecutionGateway, error) {
+	gateway := &amqpGateway{
+		url:        url,
+		declared:   make(map[string]bool),
+		claimCheck: claimCheck,
+	}
+	if err := gateway.connect(); err != nil {
+		return nil, err
+	}
+
+	return gateway, nil
+}
+
+func (g *amqpGateway) SupportsTransport(transport entities.StepTransport) bool {
+	return transport == entities.StepTransportAMQP
+}
+
+func (g *amqpGateway) SendStepToExecute(
+	sagaName string,
+	sagaExecution entities.SagaExecution,
+	sagaStep entities.StepExecution,
+	stepsExecution []entities.StepExecution,
+	isCompensation bool,
+) error {
+	marshaledValue, err := marshalStepToExecute(
+		g.claimCheck, sagaName, sagaExecution, sagaStep, stepsExecution, isCompensation,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Only one step is published at a time, so the confirmation awaited is always the one of the last publish
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if err := g.connect(); err != nil {
+		return err
+	}
+	if err := g.publish(sagaName, sagaStep.Name, marshaledValue); err != nil {
+		g.reset()
+		return err
+	}
+
+	return nil
+}
+
+func (g *amqpGateway) publish(sagaName, stepName string, body []byte) error {
+	exchange := AMQPStepExchange(sagaName)
+	if err := g.declareStep(exchange, sagaName, stepName); err != nil {
+		return err
+	}
+
+	err := g.channel.Publish(exchange, stepName, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         body,
+	})
+	if err != nil {
+		return fmt.Errorf("error sending step to be executed: %w", err)
+	}
+	g.published++
+
+	timeout := time.After(amqpConfirmTimeout)
+	for {
+		select {
+		case confirmation, ok := <-g.confirms:
+			if !ok {
+				return fmt.Errorf("%w: channel closed", ErrStepNotConfirmed)
+			}
+			// Delivery tags count the publishes of the channel, an older tag isn't the confirmation of this step
+			if confirmation.DeliveryTag < g.published {
+				continue
+			}
+			if !confirmation.Ack {
+				return ErrStepNotConfirmed
+			}
+			return nil
+		case <-timeout:
+			return fmt.Errorf("%w: timeout waiting for the confirmation", ErrStepNotConfirmed)
+		}
+	}
+}
+
+// connect opens the connection and the confirm channel when they aren't open yet.
+func (g *amqpGateway) connect() error {
+	if g.channel != nil {
+		return nil
+	}
+
+	if g.connection == nil || g.connection.IsClosed() {
+		connection, err := amqp.Dial(g.url)
+		if err != nil {
+			return fmt.Errorf("error connecting to rabbitmq: %w", err)
+		}
+		g.connection = connection
+	}
+
+	channel, err := g.connection.Channel()
+	if err != nil {
+		_ = g.connection.Close()
+		g.connection = nil
+		return fmt.Errorf("error opening amqp channel: %w", err)
+	}
+	if err := channel.Confirm(false); err != nil {
+		_ = channel.Close()
+		return fmt.Errorf("error enabling publisher confirms: %w", err)
+	}
+
+	g.channel = channel
+	g.confirms = channel.NotifyPublish(make(chan amqp.Confirmation, 1))
+	g.published = 0
+	// The broker may have lost the declarations along with the connection
+	g.declared = make(map[string]bool)
+	return nil
+}
+
+// reset drops the channel, a confirmation arriving late can't be taken for the one of another step.
+func (g *amqpGateway) reset() {
+	if g.channel != nil {
+		_ = g.channel.Close()
+	}
+	g.channel = nil
+	g.confirms = nil
+}
+
+func (g *amqpGateway) declareStep(exchange, sagaName, stepName string) error {
+	queue := AMQPStepQueue(sagaName, stepName)
+	if g.declared[queue] {
+		return nil
+	}
+
+	if err := g.channel.ExchangeDeclare(exchange, amqp.ExchangeDirect, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("error declaring exchange %s: %w", exchange, err)
+	}
+	if _, err := g.channel.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("error declaring queue %s: %w", queue, err)
+	}
+	if err := g.channel.QueueBind(queue, stepName, exchange, false, nil); err != nil {
+		return fmt.Errorf("error binding queue %s: %w", queue, err)
+	}
+
+	g.declared[queue] = true
+	return nil
+}
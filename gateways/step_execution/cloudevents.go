@@ -0,0 +1,186 @@
+package step_execution
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// CloudEventsMode is how the Kafka messages are wrapped in CloudEvents 1.0, they aren't by default.
+type CloudEventsMode string
+
+const (
+	CloudEventsDisabled CloudEventsMode = ""
+	// CloudEventsStructured sends the whole event, attributes and data, as the message value
+	CloudEventsStructured CloudEventsMode = "structured"
+	// CloudEventsBinary sends the attributes as `ce_` headers and the data as the message value
+	CloudEventsBinary CloudEventsMode = "binary"
+)
+
+// Types of the step commands and of the results sent back by the workers
+const (
+	CloudEventTypeExecute     = "sukuna.step.execute"
+	CloudEventTypeCompensate  = "sukuna.step.compensate"
+	CloudEventTypeSucceeded   = "sukuna.step.succeeded"
+	CloudEventTypeFailed      = "sukuna.step.failed"
+	CloudEventTypeCompensated = "sukuna.step.compensated"
+)
+
+const (
+	cloudEventsSpecVersion  = "1.0"
+	cloudEventsContentType  = "application/cloudevents+json"
+	contentTypeHeader       = "content-type"
+	cloudEventsHeaderPrefix = "ce_"
+)
+
+var ErrInvalidCloudEvent = errors.New("invalid cloud event")
+
+// CloudEvent is the structured JSON format of CloudEvents 1.0, the data is always JSON.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            *time.Time      `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	// DataBase64 carries the data of the structured events that isn't JSON, like protobuf messages
+	DataBase64 []byte `json:"data_base64,omitempty"`
+}
+
+func ParseCloudEventsMode(value string) (CloudEventsMode, error) {
+	switch mode := CloudEventsMode(value); mode {
+	case CloudEventsDisabled, CloudEventsStructured, CloudEventsBinary:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid cloud events mode %q, it must be structured or binary", value)
+	}
+}
+
+// CloudEventResult is the step result carried by a result event type, empty for other types.
+func CloudEventResult(eventType string) string {
+	switch eventType {
+	case CloudEventTypeSucceeded:
+		return "success"
+	case CloudEventTypeFailed:
+		return "error"
+	case CloudEventTypeCompensated:
+		return "compensated"
+	default:
+		return ""
+	}
+}
+
+func newStepCloudEvent(
+	sagaName string,
+	executionID string,
+	stepIndex int,
+	isCompensation bool,
+	dataContentType string,
+	data []byte,
+) CloudEvent {
+	eventType := CloudEventTypeExecute
+	if isCompensation {
+		eventType = CloudEventTypeCompensate
+	}
+	now := time.Now().UTC()
+
+	return CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              fmt.Sprintf("%s-%d-%s", executionID, stepIndex, stepAction(isCompensation)),
+		Source:          fmt.Sprintf("/sukuna/sagas/%s", sagaName),
+		Type:            eventType,
+		Subject:         fmt.Sprintf("executions/%s/steps/%d", executionID, stepIndex),
+		Time:            &now,
+		DataContentType: dataContentType,
+		Data:            data,
+	}
+}
+
+// encodeKafkaCloudEvent returns the message value and the headers of the event in the given mode.
+func encodeKafkaCloudEvent(mode CloudEventsMode, event CloudEvent) ([]byte, []sarama.RecordHeader, error) {
+	if mode == CloudEventsBinary {
+		headers := []sarama.RecordHeader{
+			{Key: []byte(contentTypeHeader), Value: []byte(event.DataContentType)},
+			{Key: []byte(cloudEventsHeaderPrefix + "specversion"), Value: []byte(event.SpecVersion)},
+			{Key: []byte(cloudEventsHeaderPrefix + "id"), Value: []byte(event.ID)},
+			{Key: []byte(cloudEventsHeaderPrefix + "source"), Value: []byte(event.Source)},
+			{Key: []byte(cloudEventsHeaderPrefix + "type"), Value: []byte(event.Type)},
+			{Key: []byte(cloudEventsHeaderPrefix + "subject"), Value: []byte(event.Subject)},
+			{Key: []byte(cloudEventsHeaderPrefix + "time"), Value: []byte(event.Time.Format(time.RFC3339Nano))},
+		}
+		return event.Data, headers, nil
+	}
+
+	if event.DataContentType != ContentTypeJSON {
+		event.DataBase64, event.Data = event.Data, nil
+	}
+	value, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error marshaling the cloud event: %w", err)
+	}
+	headers := []sarama.RecordHeader{{Key: []byte(contentTypeHeader), Value: []byte(cloudEventsContentType)}}
+
+	return value, headers, nil
+}
+
+// DecodeKafkaCloudEvent reads the event of a message in structured or binary mode,
+// returning false when the message isn't a CloudEvent.
+func DecodeKafkaCloudEvent(message *sarama.ConsumerMessage) (CloudEvent, bool, error) {
+	event := CloudEvent{Data: message.Value}
+	isStructured := false
+	for _, header := range message.Headers {
+		key := strings.ToLower(string(header.Key))
+		value := string(header.Value)
+
+		switch {
+		case key == contentTypeHeader:
+			isStructured = strings.HasPrefix(value, cloudEventsContentType)
+			event.DataContentType = value
+		case key == cloudEventsHeaderPrefix+"specversion":
+			event.SpecVersion = value
+		case key == cloudEventsHeaderPrefix+"id":
+			event.ID = value
+		case key == cloudEventsHeaderPrefix+"source":
+			event.Source = value
+		case key == cloudEventsHeaderPrefix+"type":
+			event.Type = value
+		case key == cloudEventsHeaderPrefix+"subject":
+			event.Subject = value
+		case key == cloudEventsHeaderPrefix+"time":
+			if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				event.Time = &parsed
+			}
+		}
+	}
+
+	if isStructured {
+		var structured CloudEvent
+		if err := json.Unmarshal(message.Value, &structured); err != nil {
+			return CloudEvent{}, true, fmt.Errorf("%w: %v", ErrInvalidCloudEvent, err)
+		}
+		event = structured
+		if len(event.DataBase64) > 0 {
+			event.Data = event.DataBase64
+		}
+	}
+	if event.SpecVersion == "" {
+		return CloudEvent{}, false, nil
+	}
+	if event.SpecVersion != cloudEventsSpecVersion || event.ID == "" || event.Source == "" || event.Type == "" {
+		return CloudEvent{}, true, ErrInvalidCloudEvent
+	}
+
+	return event, true, nil
+}
+
+// IsCloudEventsHeader tells whether the header is one of the CloudEvents attributes or the content type.
+func IsCloudEventsHeader(key []byte) bool {
+	lowerKey := strings.ToLower(string(key))
+	return lowerKey == contentTypeHeader || strings.HasPrefix(lowerKey, cloudEventsHeaderPrefix)
+}
@@ -0,0 +1,109 @@
+package step_execution
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// Reasons of the messages sent to the dead-letter topic
+const (
+	DeadLetterInvalidMessage = "invalid_message"
+	DeadLetterHandlingFailed = "handling_failed"
+)
+
+// Headers added to the dead-lettered messages, along with their own headers, to tell why and where they failed.
+const (
+	KafkaHeaderDeadLetterReason    = "sukuna-dlq-reason"
+	KafkaHeaderDeadLetterError     = "sukuna-dlq-error"
+	KafkaHeaderDeadLetterAttempts  = "sukuna-dlq-attempts"
+	KafkaHeaderDeadLetterTopic     = "sukuna-dlq-topic"
+	KafkaHeaderDeadLetterPartition = "sukuna-dlq-partition"
+	KafkaHeaderDeadLetterOffset    = "sukuna-dlq-offset"
+	KafkaHeaderDeadLetterFailedAt  = "sukuna-dlq-failed-at"
+
+	deadLetterHeaderPrefix = "sukuna-dlq-"
+	deadLetterTopicSuffix  = "dlq"
+)
+
+// DeadLetterTopic is where the results that can't be handled are sent, `<result topic>-dlq`.
+func (n TopicNaming) DeadLetterTopic() string {
+	return n.name(KafkaResultTopic, deadLetterTopicSuffix)
+}
+
+// DeadLetterQueue sends the messages that can't be handled to the dead-letter topic, keeping their key, value and headers.
+type DeadLetterQueue struct {
+	producer sarama.SyncProducer
+	topic    string
+}
+
+func NewDeadLetterQueue(producer sarama.SyncProducer, topic string) DeadLetterQueue {
+	return DeadLetterQueue{producer: producer, topic: topic}
+}
+
+func (q DeadLetterQueue) Send(message *sarama.ConsumerMessage, reason string, attempts int, cause error) error {
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers)+7)
+	for _, header := range message.Headers {
+		// A message failing again after being re-driven only keeps its last failure
+		if isDeadLetterHeader(header.Key) {
+			continue
+		}
+		headers = append(headers, *header)
+	}
+	headers = append(
+		headers,
+		sarama.RecordHeader{Key: []byte(KafkaHeaderDeadLetterReason), Value: []byte(reason)},
+		sarama.RecordHeader{Key: []byte(KafkaHeaderDeadLetterError), Value: []byte(cause.Error())},
+		sarama.RecordHeader{Key: []byte(KafkaHeaderDeadLetterAttempts), Value: []byte(strconv.Itoa(attempts))},
+		sarama.RecordHeader{Key: []byte(KafkaHeaderDeadLetterTopic), Value: []byte(message.Topic)},
+		sarama.RecordHeader{
+			Key: []byte(KafkaHeaderDeadLetterPartition), Value: []byte(strconv.Itoa(int(message.Partition))),
+		},
+		sarama.RecordHeader{
+			Key: []byte(KafkaHeaderDeadLetterOffset), Value: []byte(strconv.FormatInt(message.Offset, 10)),
+		},
+		sarama.RecordHeader{
+			Key: []byte(KafkaHeaderDeadLetterFailedAt), Value: []byte(time.Now().UTC().Format(time.RFC3339)),
+		},
+	)
+
+	_, _, err := q.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   q.topic,
+		Key:     sarama.ByteEncoder(message.Key),
+		Value:   sarama.ByteEncoder(message.Value),
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("error sending the message to the dead-letter topic: %w", err)
+	}
+
+	return nil
+}
+
+// RedriveMessage is the dead-lettered message as it was before failing, to be sent again to its topic.
+// Messages without the topic header are sent to the default topic.
+func RedriveMessage(message *sarama.ConsumerMessage, defaultTopic string) *sarama.ProducerMessage {
+	redriven := &sarama.ProducerMessage{
+		Topic: defaultTopic,
+		Key:   sarama.ByteEncoder(message.Key),
+		Value: sarama.ByteEncoder(message.Value),
+	}
+	for _, header := range message.Headers {
+		if string(header.Key) == KafkaHeaderDeadLetterTopic && len(header.Value) > 0 {
+			redriven.Topic = string(header.Value)
+		}
+		if isDeadLetterHeader(header.Key) {
+			continue
+		}
+		redriven.Headers = append(redriven.Headers, *header)
+	}
+
+	return redriven
+}
+
+func isDeadLetterHeader(key []byte) bool {
+	return strings.HasPrefix(string(key), deadLetterHeaderPrefix)
+}
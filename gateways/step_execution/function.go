@@ -0,0 +1,171 @@
+package step_execution
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+)
+
+// FunctionStepErrorCode is the code of the step errors that aren't an `entities.StepError`.
+const FunctionStepErrorCode = "STEP_FAILED"
+
+var (
+	ErrStepFunctionNotRegistered = errors.New("step function not registered")
+	ErrStepFunctionPanicked      = errors.New("step function panicked")
+)
+
+// StepFunc executes a step, receiving the same payload workers receive and returning the step output.
+// Returning an `entities.StepError` sets the step error code, any other error is a FunctionStepErrorCode error.
+type StepFunc func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error)
+
+// CompensationFunc undoes a step, receiving the same payload the step received.
+type CompensationFunc func(ctx context.Context, payload json.RawMessage) error
+
+// StepFunctions are the handlers of a step, steps without a compensation have nothing to undo.
+type StepFunctions struct {
+	Execute    StepFunc
+	Compensate CompensationFunc
+}
+
+type FunctionGateway struct {
+	mutex     *sync.RWMutex
+	functions map[string]StepFunctions
+	results   ResultHandler
+}
+
+// NewFunctionGateway runs the steps as Go functions in the same process, whatever their transport is.
+// Functions run in background and their results are handed to the result handler, like http steps.
+func NewFunctionGateway(results ResultHandler) FunctionGateway {
+	return FunctionGateway{
+		mutex:     &sync.RWMutex{},
+		functions: make(map[string]StepFunctions),
+		results:   results,
+	}
+}
+
+// Register sets the functions of a saga step, names are formatted like the saga and step names are.
+func (g FunctionGateway) Register(sagaName, stepName string, functions StepFunctions) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.functions[functionKey(sagas.FormatName(sagaName), sagas.FormatName(stepName))] = functions
+}
+
+func (g FunctionGateway) SupportsTransport(_ entities.StepTransport) bool {
+	return true
+}
+
+func (g FunctionGateway) SendStepToExecute(
+	sagaName string,
+	sagaExecution entities.SagaExecution,
+	sagaStep entities.StepExecution,
+	stepsExecution []entities.StepExecution,
+	isCompensation bool,
+) error {
+	g.mutex.RLock()
+	functions, ok := g.functions[functionKey(sagaName, sagaStep.Name)]
+	g.mutex.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStepFunctionNotRegistered, functionKey(sagaName, sagaStep.Name))
+	}
+
+	payload, err := buildStepPayload(sagaExecution, sagaStep, stepsExecution)
+	if err != nil {
+		return fmt.Errorf("error building the step payload: %w", err)
+	}
+
+	go func() {
+		ctx := context.Background()
+		result := sagas.StepResultVO{
+			SagaName:    sagaName,
+			StepIndex:   sagaStep.Index,
+			ExecutionID: sagaExecution.SagaExecutionID,
+		}
+
+		if isCompensation {
+			if !g.compensate(ctx, functions.Compensate, payload) {
+				return
+			}
+			result.Result = "compensated"
+		} else {
+			output, err := g.execute(ctx, functions.Execute, payload)
+			if err != nil {
+				result.Result = "error"
+				result.Error = newFunctionStepError(err)
+			} else {
+				result.Result = "success"
+				result.Output = output
+			}
+		}
+
+		if err := g.results(ctx, result); err != nil {
+			log.Printf("error handling the step %s result: %v", sagaStep.Name, err)
+		}
+	}()
+
+	return nil
+}
+
+// execute runs the step, a panic is returned as an error so the step fails instead of the whole process.
+func (g FunctionGateway) execute(
+	ctx context.Context,
+	execute StepFunc,
+	payload json.RawMessage,
+) (output json.RawMessage, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%w: %v", ErrStepFunctionPanicked, recovered)
+		}
+	}()
+
+	return execute(ctx, payload)
+}
+
+// compensate returns false when the compensation failed or panicked, the execution is kept compensating since the
+// service has no result for failed compensations.
+func (g FunctionGateway) compensate(
+	ctx context.Context,
+	compensation CompensationFunc,
+	payload json.RawMessage,
+) (compensated bool) {
+	if compensation == nil {
+		return true
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("error compensating the step: %v: %v", ErrStepFunctionPanicked, recovered)
+			compensated = false
+		}
+	}()
+
+	if err := compensation(ctx, payload); err != nil {
+		log.Printf("error compensating the step: %v", err)
+		return false
+	}
+
+	return true
+}
+
+func newFunctionStepError(err error) *entities.StepError {
+	var stepError entities.StepError
+	if errors.As(err, &stepError) {
+		return &stepError
+	}
+	var stepErrorPointer *entities.StepError
+	if errors.As(err, &stepErrorPointer) && stepErrorPointer != nil {
+		return stepErrorPointer
+	}
+
+	return &entities.StepError{Code: FunctionStepErrorCode, Message: err.Error()}
+}
+
+func functionKey(sagaName, stepName string) string {
+	return fmt.Sprintf("%s-%s", sagaName, stepName)
+}
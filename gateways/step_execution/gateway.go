@@ -1,52 +1,141 @@
 package step_execution
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+
 	"github.com/Shopify/sarama"
 	"github.com/google/uuid"
 	"github.com/thepabloaguilar/sukuna/core/entities"
 	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/gateways/payload_store"
+	"github.com/thepabloaguilar/sukuna/gateways/step_execution/steppb"
 )
 
 type StepToExecute struct {
-	SagaName string `json:"saga_name"`
-	StepIndex int `json:"step_index"`
-	ExecutionID uuid.UUID `json:"saga_id"`
-	Payload json.RawMessage `json:"payload"`
-	IsCompensation bool `json:"is_compensation"`
+	SagaName         string          `json:"saga_name"`
+	StepIndex        int             `json:"step_index"`
+	ExecutionID      uuid.UUID       `json:"saga_id"`
+	Payload          json.RawMessage `json:"payload"`
+	PayloadReference string          `json:"payload_reference,omitempty"`
+	IsCompensation   bool            `json:"is_compensation"`
+}
+
+// ResolvePayload returns the step payload, loading it from the payload store when the message carries a reference.
+func ResolvePayload(ctx context.Context, claimCheck payload_store.ClaimCheck, step StepToExecute) (json.RawMessage, error) {
+	return claimCheck.Resolve(ctx, step.Payload, step.PayloadReference)
+}
+
+type KafkaConfig struct {
+	CloudEventsMode CloudEventsMode
+	TopicNaming     TopicNaming
+	// TopicProvisioner creates the step topics when sagas are created, without it they must be created beforehand
+	TopicProvisioner *TopicProvisioner
 }
 
 type gateway struct {
-	producer sarama.SyncProducer
+	producer   sarama.SyncProducer
+	claimCheck payload_store.ClaimCheck
+	config     KafkaConfig
+}
+
+func NewGateway(
+	producer sarama.SyncProducer,
+	claimCheck payload_store.ClaimCheck,
+	config KafkaConfig,
+) sagas.StepExecutionGateway {
+	return gateway{producer: producer, claimCheck: claimCheck, config: config}
 }
 
-func NewGateway(producer sarama.SyncProducer) sagas.StepExecutionGateway {
-	return gateway{producer: producer}
+func (g gateway) SupportsTransport(transport entities.StepTransport) bool {
+	return transport == entities.StepTransportKafka
+}
+
+// ValidateStep reads the kafka config of the step, so an invalid encoding or topic is refused with the saga.
+func (g gateway) ValidateStep(step entities.SagaStep) error {
+	_, err := parseKafkaStepConfig(step.TransportConfig)
+	return err
+}
+
+// ProvisionSteps creates the topics of the kafka steps when there's a topic provisioner.
+func (g gateway) ProvisionSteps(_ context.Context, sagaName string, steps []entities.SagaStep) error {
+	if g.config.TopicProvisioner == nil {
+		return nil
+	}
+
+	topics := make([]string, 0, len(steps))
+	for _, step := range steps {
+		config, err := parseKafkaStepConfig(step.TransportConfig)
+		if err != nil {
+			return err
+		}
+		topics = append(topics, g.config.TopicNaming.stepTopics(sagaName, step.Name, config)...)
+	}
+
+	return g.config.TopicProvisioner.CreateTopics(topics...)
 }
 
 func (g gateway) SendStepToExecute(
 	sagaName string,
 	sagaExecution entities.SagaExecution,
 	sagaStep entities.StepExecution,
+	stepsExecution []entities.StepExecution,
 	isCompensation bool,
 ) error {
-	value := StepToExecute{
-		SagaName: sagaName,
-		StepIndex: sagaStep.Index,
-		ExecutionID: sagaExecution.SagaExecutionID,
-		Payload: sagaExecution.Payload,
-		IsCompensation: isCompensation,
+	config, err := parseKafkaStepConfig(sagaStep.TransportConfig)
+	if err != nil {
+		return err
 	}
 
-	marshaledValue, err := json.Marshal(value)
+	step, err := buildStepToExecute(g.claimCheck, sagaName, sagaExecution, sagaStep, stepsExecution, isCompensation)
+	if err != nil {
+		return err
+	}
+	contentType := ContentTypeJSON
+	var marshaledValue []byte
+	if config.Encoding == StepEncodingProtobuf {
+		contentType = ProtobufContentType(&steppb.StepToExecute{})
+		marshaledValue, err = marshalStepToExecuteProto(step)
+	} else {
+		marshaledValue, err = json.Marshal(step)
+	}
 	if err != nil {
 		return fmt.Errorf("error marsheling the step: %w", err)
 	}
 
+	headers := KafkaHeaders{
+		SagaName:       sagaName,
+		StepName:       sagaStep.Name,
+		StepIndex:      sagaStep.Index,
+		Attempt:        sagaStep.Attempt,
+		IsCompensation: isCompensation,
+		CorrelationID:  sagaExecution.SagaExecutionID.String(),
+	}
+	recordHeaders := headers.RecordHeaders()
+
+	if g.config.CloudEventsMode == CloudEventsDisabled {
+		recordHeaders = append(recordHeaders, sarama.RecordHeader{
+			Key: []byte(contentTypeHeader), Value: []byte(contentType),
+		})
+	} else {
+		event := newStepCloudEvent(
+			sagaName, sagaExecution.SagaExecutionID.String(), sagaStep.Index, isCompensation, contentType, marshaledValue,
+		)
+		var eventHeaders []sarama.RecordHeader
+		marshaledValue, eventHeaders, err = encodeKafkaCloudEvent(g.config.CloudEventsMode, event)
+		if err != nil {
+			return err
+		}
+		recordHeaders = append(recordHeaders, eventHeaders...)
+	}
+
+	// The execution id as key keeps the messages of an execution in the same partition
 	_, _, err = g.producer.SendMessage(&sarama.ProducerMessage{
-		Topic:     fmt.Sprintf("%s-%s", sagaName, sagaStep.Name),
-		Value:     sarama.ByteEncoder(marshaledValue),
+		Topic:   g.config.TopicNaming.stepTopic(sagaName, sagaStep.Name, config, isCompensation),
+		Key:     sarama.StringEncoder(sagaExecution.SagaExecutionID.String()),
+		Value:   sarama.ByteEncoder(marshaledValue),
+		Headers: recordHeaders,
 	})
 	if err != nil {
 		return fmt.Errorf("error sending step to be executed: %w", err)
@@ -54,3 +143,63 @@ func (g gateway) SendStepToExecute(
 
 	return nil
 }
+
+// marshalStepToExecute builds the JSON message received by the workers, moving big payloads to the payload store.
+func marshalStepToExecute(
+	claimCheck payload_store.ClaimCheck,
+	sagaName string,
+	sagaExecution entities.SagaExecution,
+	sagaStep entities.StepExecution,
+	stepsExecution []entities.StepExecution,
+	isCompensation bool,
+) ([]byte, error) {
+	value, err := buildStepToExecute(claimCheck, sagaName, sagaExecution, sagaStep, stepsExecution, isCompensation)
+	if err != nil {
+		return nil, err
+	}
+
+	marshaledValue, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("error marsheling the step: %w", err)
+	}
+
+	return marshaledValue, nil
+}
+
+func buildStepToExecute(
+	claimCheck payload_store.ClaimCheck,
+	sagaName string,
+	sagaExecution entities.SagaExecution,
+	sagaStep entities.StepExecution,
+	stepsExecution []entities.StepExecution,
+	isCompensation bool,
+) (StepToExecute, error) {
+	payload, err := buildStepPayload(sagaExecution, sagaStep, stepsExecution)
+	if err != nil {
+		return StepToExecute{}, fmt.Errorf("error building the step payload: %w", err)
+	}
+
+	key := payload_store.StepPayloadsPrefix(sagaName, sagaExecution.SagaExecutionID) +
+		fmt.Sprintf("%d-%s.json", sagaStep.Index, stepAction(isCompensation))
+	payload, reference, err := claimCheck.Offload(context.Background(), key, payload)
+	if err != nil {
+		return StepToExecute{}, err
+	}
+
+	return StepToExecute{
+		SagaName:         sagaName,
+		StepIndex:        sagaStep.Index,
+		ExecutionID:      sagaExecution.SagaExecutionID,
+		Payload:          payload,
+		PayloadReference: reference,
+		IsCompensation:   isCompensation,
+	}, nil
+}
+
+func stepAction(isCompensation bool) string {
+	if isCompensation {
+		return "compensation"
+	}
+
+	return "execution"
+}
@@ -0,0 +1,374 @@
+package step_execution
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+)
+
+const (
+	defaultHTTPTimeout  = 30 * time.Second
+	maxHTTPResponseSize = 1 << 20
+
+	httpRetryInitialBackoff  = time.Second
+	httpRetryMaxBackoff      = time.Minute
+	httpResultAttempts       = 5
+	httpCompensationAttempts = 10
+
+	// HTTPTransportErrorCode is the code of the retryable errors of steps whose request failed without an answer
+	HTTPTransportErrorCode = "TRANSPORT_ERROR"
+	// HTTPHeaderAttempt carries the attempt of the step, as the `sukuna-attempt` header of the kafka steps
+	HTTPHeaderAttempt = "Sukuna-Attempt"
+)
+
+// ResultHandler receives the results of the transports answering synchronously, usually `sagas.Service.HandleStepResult`.
+type ResultHandler func(ctx context.Context, result sagas.StepResultVO) error
+
+// AttemptCounter counts a new attempt of a step sent again by its transport, usually
+// `sagas.SagaRepository.IncrementSagaStepExecutionAttempt`.
+type AttemptCounter func(ctx context.Context, index int, executionID uuid.UUID) (int, error)
+
+// httpStepConfig is the `transport_config` of http steps.
+//
+// Compensations are sent to `compensation_url` or, when it's empty, to `url` with `is_compensation` set.
+// Responses with one of the `error_status_codes` are step errors, any other non 2xx status is a transport failure:
+// a retryable step error for executions, retried for compensations until they run out of attempts, which fails the
+// execution.
+type httpStepConfig struct {
+	URL              string `json:"url"`
+	CompensationURL  string `json:"compensation_url"`
+	ErrorStatusCodes []int  `json:"error_status_codes"`
+	Timeout          string `json:"timeout"`
+}
+
+// httpStepError is read from the body of error responses, it's the same error workers send through the other transports
+type httpStepError struct {
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	Retryable bool            `json:"retryable"`
+	Details   json.RawMessage `json:"details"`
+}
+
+type httpGateway struct {
+	ctx      context.Context
+	client   *http.Client
+	results  ResultHandler
+	attempts AttemptCounter
+}
+
+// NewHTTPGateway POSTs the step to the URL configured in the step and hands the response to the result handler.
+// Requests are made in background, so a saga made of http steps doesn't hold the caller until it finishes, within ctx:
+// cancelling it stops the requests and retries in progress, which are lost. Every retry of a compensation is counted
+// as a new attempt of the step with attempts.
+func NewHTTPGateway(
+	ctx context.Context,
+	client *http.Client,
+	results ResultHandler,
+	attempts AttemptCounter,
+) sagas.StepExecutionGateway {
+	return httpGateway{ctx: ctx, client: client, results: results, attempts: attempts}
+}
+
+func (g httpGateway) SupportsTransport(transport entities.StepTransport) bool {
+	return transport == entities.StepTransportHTTP
+}
+
+func (g httpGateway) ValidateStep(step entities.SagaStep) error {
+	_, _, err := parseHTTPStepConfig(step.TransportConfig)
+	return err
+}
+
+func (g httpGateway) SendStepToExecute(
+	sagaName string,
+	sagaExecution entities.SagaExecution,
+	sagaStep entities.StepExecution,
+	stepsExecution []entities.StepExecution,
+	isCompensation bool,
+) error {
+	config, timeout, err := parseHTTPStepConfig(sagaStep.TransportConfig)
+	if err != nil {
+		return err
+	}
+
+	payload, err := buildStepPayload(sagaExecution, sagaStep, stepsExecution)
+	if err != nil {
+		return fmt.Errorf("error building the step payload: %w", err)
+	}
+
+	body, err := json.Marshal(StepToExecute{
+		SagaName:       sagaName,
+		StepIndex:      sagaStep.Index,
+		ExecutionID:    sagaExecution.SagaExecutionID,
+		Payload:        payload,
+		IsCompensation: isCompensation,
+	})
+	if err != nil {
+		return fmt.Errorf("error marsheling the step: %w", err)
+	}
+
+	url := config.URL
+	if isCompensation && config.CompensationURL != "" {
+		url = config.CompensationURL
+	}
+
+	go func() {
+		result, err := g.executeWithRetries(g.ctx, sagaStep, url, body, timeout, config.ErrorStatusCodes, isCompensation)
+		if err != nil {
+			log.Printf(
+				"step %d of execution %s stopped before being answered: %v",
+				sagaStep.Index, sagaExecution.SagaExecutionID, err,
+			)
+			return
+		}
+		result.SagaName = sagaName
+		result.StepIndex = sagaStep.Index
+		result.ExecutionID = sagaExecution.SagaExecutionID
+		g.handleWithRetries(g.ctx, result)
+	}()
+
+	return nil
+}
+
+// executeWithRetries turns the transport failures of an execution into a retryable step error, so the saga moves on
+// to its compensation, while compensations are retried until they succeed or run out of attempts, which is reported
+// as a `compensation_failed` result. It only fails when ctx is done.
+func (g httpGateway) executeWithRetries(
+	ctx context.Context,
+	step entities.StepExecution,
+	url string,
+	body []byte,
+	timeout time.Duration,
+	errorStatusCodes []int,
+	isCompensation bool,
+) (sagas.StepResultVO, error) {
+	backoff := httpRetryInitialBackoff
+	stepAttempt := step.Attempt
+	for attempt := 1; ; attempt++ {
+		result, err := g.execute(ctx, url, body, stepAttempt, timeout, errorStatusCodes, isCompensation)
+		if err == nil {
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			return sagas.StepResultVO{}, ctx.Err()
+		}
+		if !isCompensation {
+			return sagas.StepResultVO{
+				Result: "error",
+				Error:  &entities.StepError{Code: HTTPTransportErrorCode, Message: err.Error(), Retryable: true},
+			}, nil
+		}
+		if attempt == httpCompensationAttempts {
+			log.Printf("error sending compensation to %s, giving up after %d attempts: %v", url, attempt, err)
+			return sagas.StepResultVO{
+				Result: "compensation_failed",
+				Error:  &entities.StepError{Code: HTTPTransportErrorCode, Message: err.Error()},
+			}, nil
+		}
+
+		log.Printf("error sending compensation to %s, retrying in %s: %v", url, backoff, err)
+		if err := waitHTTPBackoff(ctx, backoff); err != nil {
+			return sagas.StepResultVO{}, err
+		}
+		backoff = nextHTTPBackoff(backoff)
+		stepAttempt = g.nextAttempt(ctx, step, stepAttempt)
+	}
+}
+
+// nextAttempt counts the retry as a new attempt of the step, a failure to count it doesn't hold the retry back.
+func (g httpGateway) nextAttempt(ctx context.Context, step entities.StepExecution, attempt int) int {
+	next, err := g.attempts(ctx, step.Index, step.SagaExecutionID)
+	if err != nil {
+		log.Printf(
+			"error counting the attempt of step %d of execution %s: %v", step.Index, step.SagaExecutionID, err,
+		)
+		return attempt + 1
+	}
+
+	return next
+}
+
+func (g httpGateway) handleWithRetries(ctx context.Context, result sagas.StepResultVO) {
+	backoff := httpRetryInitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := g.results(ctx, result)
+		if err == nil {
+			return
+		}
+		if attempt == httpResultAttempts {
+			log.Printf(
+				"error handling the result of step %d of execution %s, giving up: %v",
+				result.StepIndex, result.ExecutionID, err,
+			)
+			return
+		}
+
+		log.Printf(
+			"error handling the result of step %d of execution %s, retrying in %s: %v",
+			result.StepIndex, result.ExecutionID, backoff, err,
+		)
+		if err := waitHTTPBackoff(ctx, backoff); err != nil {
+			log.Printf(
+				"stopped handling the result of step %d of execution %s: %v", result.StepIndex, result.ExecutionID, err,
+			)
+			return
+		}
+		backoff = nextHTTPBackoff(backoff)
+	}
+}
+
+// waitHTTPBackoff waits for the backoff to pass, failing when ctx is done before.
+func waitHTTPBackoff(ctx context.Context, backoff time.Duration) error {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func nextHTTPBackoff(backoff time.Duration) time.Duration {
+	if backoff*2 > httpRetryMaxBackoff {
+		return httpRetryMaxBackoff
+	}
+
+	return backoff * 2
+}
+
+func parseHTTPStepConfig(transportConfig []byte) (httpStepConfig, time.Duration, error) {
+	var config httpStepConfig
+	if err := json.Unmarshal(transportConfig, &config); err != nil {
+		return httpStepConfig{}, 0, fmt.Errorf("error reading http step config: %w", err)
+	}
+	if config.URL == "" {
+		return httpStepConfig{}, 0, errors.New("http step config has no url")
+	}
+	if err := validateHTTPStepURL(config.URL); err != nil {
+		return httpStepConfig{}, 0, err
+	}
+	if config.CompensationURL != "" {
+		if err := validateHTTPStepURL(config.CompensationURL); err != nil {
+			return httpStepConfig{}, 0, err
+		}
+	}
+
+	timeout := defaultHTTPTimeout
+	if config.Timeout != "" {
+		parsed, err := time.ParseDuration(config.Timeout)
+		if err != nil {
+			return httpStepConfig{}, 0, fmt.Errorf("invalid http step timeout: %w", err)
+		}
+		if parsed <= 0 {
+			return httpStepConfig{}, 0, fmt.Errorf("invalid http step timeout: %s", config.Timeout)
+		}
+		timeout = parsed
+	}
+
+	return config, timeout, nil
+}
+
+func validateHTTPStepURL(rawURL string) error {
+	stepURL, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid http step url: %w", err)
+	}
+	if (stepURL.Scheme != "http" && stepURL.Scheme != "https") || stepURL.Host == "" {
+		return fmt.Errorf("invalid http step url %q", rawURL)
+	}
+
+	return nil
+}
+
+func (g httpGateway) execute(
+	ctx context.Context,
+	url string,
+	body []byte,
+	attempt int,
+	timeout time.Duration,
+	errorStatusCodes []int,
+	isCompensation bool,
+) (sagas.StepResultVO, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return sagas.StepResultVO{}, fmt.Errorf("error creating request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HTTPHeaderAttempt, strconv.Itoa(attempt))
+
+	response, err := g.client.Do(request)
+	if errors.Is(err, context.DeadlineExceeded) && !isCompensation {
+		return sagas.StepResultVO{
+			Result: "error",
+			Error:  &entities.StepError{Code: "TIMEOUT", Message: err.Error(), Retryable: true},
+		}, nil
+	}
+	if err != nil {
+		return sagas.StepResultVO{}, fmt.Errorf("error sending request: %w", err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(io.LimitReader(response.Body, maxHTTPResponseSize))
+	if err != nil {
+		return sagas.StepResultVO{}, fmt.Errorf("error reading response: %w", err)
+	}
+
+	switch {
+	case response.StatusCode >= 200 && response.StatusCode < 300 && isCompensation:
+		return sagas.StepResultVO{Result: "compensated"}, nil
+	case response.StatusCode >= 200 && response.StatusCode < 300:
+		result := sagas.StepResultVO{Result: "success"}
+		if json.Valid(responseBody) {
+			result.Output = responseBody
+		}
+		return result, nil
+	case containsStatusCode(errorStatusCodes, response.StatusCode) && !isCompensation:
+		return sagas.StepResultVO{Result: "error", Error: newHTTPStepError(response.StatusCode, responseBody)}, nil
+	default:
+		return sagas.StepResultVO{}, fmt.Errorf("unexpected response status %d", response.StatusCode)
+	}
+}
+
+func containsStatusCode(statusCodes []int, statusCode int) bool {
+	for _, code := range statusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newHTTPStepError reads the step error from the response body, falling back to the status code.
+func newHTTPStepError(statusCode int, body []byte) *entities.StepError {
+	var stepError httpStepError
+	if err := json.Unmarshal(body, &stepError); err == nil && stepError.Code != "" {
+		return &entities.StepError{
+			Code:      stepError.Code,
+			Message:   stepError.Message,
+			Retryable: stepError.Retryable,
+			Details:   stepError.Details,
+		}
+	}
+
+	return &entities.StepError{
+		Code:    fmt.Sprintf("HTTP_%d", statusCode),
+		Message: http.StatusText(statusCode),
+	}
+}
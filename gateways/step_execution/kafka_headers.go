@@ -0,0 +1,65 @@
+package step_execution
+
+import (
+	"strconv"
+
+	"github.com/Shopify/sarama"
+)
+
+// Headers of the step messages, workers send them back with the results so both can be correlated
+// without reading the message values.
+const (
+	KafkaHeaderSagaName       = "sukuna-saga-name"
+	KafkaHeaderStepName       = "sukuna-step-name"
+	KafkaHeaderStepIndex      = "sukuna-step-index"
+	KafkaHeaderAttempt        = "sukuna-attempt"
+	KafkaHeaderIsCompensation = "sukuna-is-compensation"
+	// KafkaHeaderCorrelationID only repeats the execution id, the message key, for the tools reading the headers alone
+	KafkaHeaderCorrelationID = "sukuna-correlation-id"
+)
+
+// KafkaHeaders are the values of the step headers. The attempt counts the sends of the step, its compensations
+// included, and the correlation id is the execution id, the same as the message key.
+type KafkaHeaders struct {
+	SagaName       string
+	StepName       string
+	StepIndex      int
+	Attempt        int
+	IsCompensation bool
+	CorrelationID  string
+}
+
+func (h KafkaHeaders) RecordHeaders() []sarama.RecordHeader {
+	return []sarama.RecordHeader{
+		{Key: []byte(KafkaHeaderSagaName), Value: []byte(h.SagaName)},
+		{Key: []byte(KafkaHeaderStepName), Value: []byte(h.StepName)},
+		{Key: []byte(KafkaHeaderStepIndex), Value: []byte(strconv.Itoa(h.StepIndex))},
+		{Key: []byte(KafkaHeaderAttempt), Value: []byte(strconv.Itoa(h.Attempt))},
+		{Key: []byte(KafkaHeaderIsCompensation), Value: []byte(strconv.FormatBool(h.IsCompensation))},
+		{Key: []byte(KafkaHeaderCorrelationID), Value: []byte(h.CorrelationID)},
+	}
+}
+
+// ReadKafkaHeaders reads the step headers of a message, missing or invalid headers are left empty.
+func ReadKafkaHeaders(headers []*sarama.RecordHeader) KafkaHeaders {
+	var kafkaHeaders KafkaHeaders
+	for _, header := range headers {
+		value := string(header.Value)
+		switch string(header.Key) {
+		case KafkaHeaderSagaName:
+			kafkaHeaders.SagaName = value
+		case KafkaHeaderStepName:
+			kafkaHeaders.StepName = value
+		case KafkaHeaderStepIndex:
+			kafkaHeaders.StepIndex, _ = strconv.Atoi(value)
+		case KafkaHeaderAttempt:
+			kafkaHeaders.Attempt, _ = strconv.Atoi(value)
+		case KafkaHeaderIsCompensation:
+			kafkaHeaders.IsCompensation, _ = strconv.ParseBool(value)
+		case KafkaHeaderCorrelationID:
+			kafkaHeaders.CorrelationID = value
+		}
+	}
+
+	return kafkaHeaders
+}
@@ -0,0 +1,125 @@
+package step_execution
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/jsonpointer"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+)
+
+// buildStepPayload creates the message body for a step using its input mapping.
+//
+// The mapping is a JSON template, every string in the form `{{ /json/pointer }}` is replaced by the value found
+// in a document with the execution payload and the previous steps output, e.g.:
+//
+//	{
+//	  "payload": {"hotel_name": "Ibis"},
+//	  "steps": {"flight-step": {"ticket": "XPTO"}}
+//	}
+//
+// When the step has no mapping the whole execution payload is sent. References to values that aren't there, e.g. the
+// output of a step that returned none, are mapped to null so the execution isn't stuck on a step it can't send.
+func buildStepPayload(
+	sagaExecution entities.SagaExecution,
+	sagaStep entities.StepExecution,
+	stepsExecution []entities.StepExecution,
+) (json.RawMessage, error) {
+	if !hasInputMapping(sagaStep.InputMapping) {
+		return sagaExecution.Payload, nil
+	}
+
+	var template interface{}
+	if err := json.Unmarshal(sagaStep.InputMapping, &template); err != nil {
+		return nil, fmt.Errorf("error unmarshaling input mapping: %w", err)
+	}
+
+	document, err := mappingDocument(sagaExecution, stepsExecution)
+	if err != nil {
+		return nil, err
+	}
+
+	mapped, err := applyMapping(template, document)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mapped)
+}
+
+func hasInputMapping(mapping []byte) bool {
+	trimmed := strings.TrimSpace(string(mapping))
+	return trimmed != "" && trimmed != "null"
+}
+
+func mappingDocument(
+	sagaExecution entities.SagaExecution,
+	stepsExecution []entities.StepExecution,
+) (map[string]interface{}, error) {
+	var payload interface{}
+	if err := json.Unmarshal(sagaExecution.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("error unmarshaling execution payload: %w", err)
+	}
+
+	steps := make(map[string]interface{}, len(stepsExecution))
+	for _, step := range stepsExecution {
+		if len(step.Output) == 0 {
+			continue
+		}
+
+		var output interface{}
+		if err := json.Unmarshal(step.Output, &output); err != nil {
+			return nil, fmt.Errorf("error unmarshaling output of step %s: %w", step.Name, err)
+		}
+		steps[step.Name] = output
+	}
+
+	return map[string]interface{}{
+		"payload": payload,
+		"steps":   steps,
+	}, nil
+}
+
+func applyMapping(template interface{}, document interface{}) (interface{}, error) {
+	switch value := template.(type) {
+	case map[string]interface{}:
+		mapped := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			mappedItem, err := applyMapping(item, document)
+			if err != nil {
+				return nil, err
+			}
+			mapped[key] = mappedItem
+		}
+		return mapped, nil
+	case []interface{}:
+		mapped := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			mappedItem, err := applyMapping(item, document)
+			if err != nil {
+				return nil, err
+			}
+			mapped = append(mapped, mappedItem)
+		}
+		return mapped, nil
+	case string:
+		reference, ok := sagas.ParseInputReference(value)
+		if !ok {
+			return value, nil
+		}
+
+		pointer, err := jsonpointer.Parse(reference)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reference %q: %w", reference, err)
+		}
+		resolved, err := pointer.Eval(document)
+		if err != nil {
+			return nil, nil
+		}
+		return resolved, nil
+	default:
+		return value, nil
+	}
+}
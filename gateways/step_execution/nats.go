@@ -0,0 +1,89 @@
+package step_execution
+
+import (
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/gateways/payload_store"
+)
+
+const (
+	// NATSStepsStream keeps the steps of every saga, each step has its own subject, see NATSStepSubject.
+	NATSStepsStream = "SUKUNA_STEPS"
+	// NATSResultsStream keeps the results the workers publish to NATSResultSubject.
+	NATSResultsStream = "SUKUNA_RESULTS"
+	NATSResultSubject = "sukuna.results"
+
+	natsStepsSubjects = "sukuna.steps.>"
+)
+
+// NATSStepSubject is the subject the workers of a step subscribe to, the step part follows the Kafka topic naming.
+func NATSStepSubject(sagaName, stepName string) string {
+	return fmt.Sprintf("sukuna.steps.%s-%s", sagaName, stepName)
+}
+
+// EnsureNATSStreams creates the steps and results streams when they don't exist yet.
+func EnsureNATSStreams(jetStream nats.JetStreamContext) error {
+	streams := []*nats.StreamConfig{
+		{Name: NATSStepsStream, Subjects: []string{natsStepsSubjects}, Storage: nats.FileStorage},
+		{Name: NATSResultsStream, Subjects: []string{NATSResultSubject}, Storage: nats.FileStorage},
+	}
+
+	for _, stream := range streams {
+		if _, err := jetStream.StreamInfo(stream.Name); err == nil {
+			continue
+		}
+		if _, err := jetStream.AddStream(stream); err != nil {
+			return fmt.Errorf("error creating stream %s: %w", stream.Name, err)
+		}
+	}
+
+	return nil
+}
+
+type natsGateway struct {
+	jetStream  nats.JetStreamContext
+	claimCheck payload_store.ClaimCheck
+}
+
+// NewNATSGateway publishes the steps to JetStream, every publish waits for the stream acknowledgement.
+// The message id makes JetStream drop a step sent twice within its duplicates window.
+func NewNATSGateway(
+	jetStream nats.JetStreamContext,
+	claimCheck payload_store.ClaimCheck,
+) (sagas.StepExecutionGateway, error) {
+	if err := EnsureNATSStreams(jetStream); err != nil {
+		return nil, err
+	}
+
+	return natsGateway{jetStream: jetStream, claimCheck: claimCheck}, nil
+}
+
+func (g natsGateway) SupportsTransport(transport entities.StepTransport) bool {
+	return transport == entities.StepTransportNATS
+}
+
+func (g natsGateway) SendStepToExecute(
+	sagaName string,
+	sagaExecution entities.SagaExecution,
+	sagaStep entities.StepExecution,
+	stepsExecution []entities.StepExecution,
+	isCompensation bool,
+) error {
+	marshaledValue, err := marshalStepToExecute(
+		g.claimCheck, sagaName, sagaExecution, sagaStep, stepsExecution, isCompensation,
+	)
+	if err != nil {
+		return err
+	}
+
+	messageID := fmt.Sprintf("%s-%d-%s", sagaExecution.SagaExecutionID, sagaStep.Index, stepAction(isCompensation))
+	_, err = g.jetStream.Publish(NATSStepSubject(sagaName, sagaStep.Name), marshaledValue, nats.MsgId(messageID))
+	if err != nil {
+		return fmt.Errorf("error sending step to be executed: %w", err)
+	}
+
+	return nil
+}
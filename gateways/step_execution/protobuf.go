@@ -0,0 +1,179 @@
+package step_execution
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/gateways/step_execution/steppb"
+	"google.golang.org/protobuf/proto"
+)
+
+// StepEncoding is the format of the Kafka step messages, set by the `encoding` of the step transport config.
+type StepEncoding string
+
+const (
+	StepEncodingJSON     StepEncoding = "json"
+	StepEncodingProtobuf StepEncoding = "protobuf"
+)
+
+// Content types of the step messages, protobuf ones name the message of the schema,
+// e.g. `application/x-protobuf; proto=sukuna.step.v1.StepToExecute`
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+var ErrInvalidStepEncoding = errors.New("invalid step encoding")
+
+type kafkaStepConfig struct {
+	Encoding          StepEncoding `json:"encoding"`
+	Topic             string       `json:"topic"`
+	CompensationTopic string       `json:"compensation_topic"`
+}
+
+func parseKafkaStepConfig(transportConfig []byte) (kafkaStepConfig, error) {
+	config := kafkaStepConfig{Encoding: StepEncodingJSON}
+	if len(transportConfig) == 0 {
+		return config, nil
+	}
+
+	if err := json.Unmarshal(transportConfig, &config); err != nil {
+		return kafkaStepConfig{}, fmt.Errorf("error reading kafka step config: %w", err)
+	}
+	switch config.Encoding {
+	case "":
+		config.Encoding = StepEncodingJSON
+	case StepEncodingJSON, StepEncodingProtobuf:
+	default:
+		return kafkaStepConfig{}, fmt.Errorf("%w: %s", ErrInvalidStepEncoding, config.Encoding)
+	}
+	for _, topic := range []string{config.Topic, config.CompensationTopic} {
+		if err := validateTopicName(topic); err != nil {
+			return kafkaStepConfig{}, err
+		}
+	}
+
+	return config, nil
+}
+
+// ProtobufContentType is the content type of the message in the protobuf encoding.
+func ProtobufContentType(message proto.Message) string {
+	return fmt.Sprintf("%s; proto=%s", ContentTypeProtobuf, message.ProtoReflect().Descriptor().FullName())
+}
+
+func IsProtobufContentType(contentType string) bool {
+	return strings.HasPrefix(contentType, ContentTypeProtobuf)
+}
+
+// ReadContentType returns the `content-type` header, messages without it are JSON.
+func ReadContentType(headers []*sarama.RecordHeader) string {
+	for _, header := range headers {
+		if strings.ToLower(string(header.Key)) == contentTypeHeader {
+			return string(header.Value)
+		}
+	}
+
+	return ContentTypeJSON
+}
+
+func marshalStepToExecuteProto(step StepToExecute) ([]byte, error) {
+	value, err := proto.Marshal(&steppb.StepToExecute{
+		SagaName:         step.SagaName,
+		StepIndex:        int32(step.StepIndex),
+		ExecutionId:      step.ExecutionID.String(),
+		Payload:          step.Payload,
+		PayloadReference: step.PayloadReference,
+		IsCompensation:   step.IsCompensation,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling the step: %w", err)
+	}
+
+	return value, nil
+}
+
+// UnmarshalStepToExecute reads the step message in the encoding of its content type.
+func UnmarshalStepToExecute(contentType string, value []byte) (StepToExecute, error) {
+	var step StepToExecute
+	if !IsProtobufContentType(contentType) {
+		err := json.Unmarshal(value, &step)
+		return step, err
+	}
+
+	var message steppb.StepToExecute
+	if err := proto.Unmarshal(value, &message); err != nil {
+		return StepToExecute{}, err
+	}
+	executionID, err := uuid.Parse(message.ExecutionId)
+	if err != nil {
+		return StepToExecute{}, fmt.Errorf("invalid execution id: %w", err)
+	}
+
+	return StepToExecute{
+		SagaName:         message.SagaName,
+		StepIndex:        int(message.StepIndex),
+		ExecutionID:      executionID,
+		Payload:          message.Payload,
+		PayloadReference: message.PayloadReference,
+		IsCompensation:   message.IsCompensation,
+	}, nil
+}
+
+// StepResultToProto maps the `success`, `error` and `compensated` results to their protobuf values.
+func StepResultToProto(result string) steppb.StepResult {
+	switch result {
+	case "success":
+		return steppb.StepResult_STEP_RESULT_SUCCESS
+	case "error":
+		return steppb.StepResult_STEP_RESULT_ERROR
+	case "compensated":
+		return steppb.StepResult_STEP_RESULT_COMPENSATED
+	default:
+		return steppb.StepResult_STEP_RESULT_UNSPECIFIED
+	}
+}
+
+// unmarshalProtoStepResult reads a protobuf result, an invalid execution id is left empty.
+func unmarshalProtoStepResult(value []byte) (StepResult, error) {
+	var message steppb.SagaStepResult
+	if err := proto.Unmarshal(value, &message); err != nil {
+		return StepResult{}, err
+	}
+
+	result := StepResult{
+		SagaName:  message.SagaName,
+		StepIndex: int(message.StepIndex),
+		Result:    StepResultFromProto(message.Result),
+		Output:    message.Output,
+	}
+	if executionID, err := uuid.Parse(message.ExecutionId); err == nil {
+		result.ExecutionID = executionID
+	}
+	if message.Error != nil {
+		result.Error = &StepResultError{
+			Code:      message.Error.Code,
+			Message:   message.Error.Message,
+			Retryable: message.Error.Retryable,
+			Details:   message.Error.Details,
+		}
+	}
+
+	return result, nil
+}
+
+func StepResultFromProto(result steppb.StepResult) string {
+	switch result {
+	case steppb.StepResult_STEP_RESULT_SUCCESS:
+		return "success"
+	case steppb.StepResult_STEP_RESULT_ERROR:
+		return "error"
+	case steppb.StepResult_STEP_RESULT_COMPENSATED:
+		return "compensated"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,62 @@
+package step_execution
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/gateways/payload_store"
+)
+
+const (
+	// RedisResultStream is the stream the workers add the step results to.
+	RedisResultStream = "sukuna-out"
+	// RedisValueField is the entry field holding the step, and the result, JSON.
+	RedisValueField = "value"
+)
+
+// RedisStepStream is the stream the workers of a step read from, it has the same name as the Kafka topic.
+func RedisStepStream(sagaName, stepName string) string {
+	return fmt.Sprintf("%s-%s", sagaName, stepName)
+}
+
+type redisGateway struct {
+	client     *redis.Client
+	claimCheck payload_store.ClaimCheck
+}
+
+// NewRedisGateway adds the steps to their Redis streams, the workers read them through their own consumer groups.
+func NewRedisGateway(client *redis.Client, claimCheck payload_store.ClaimCheck) sagas.StepExecutionGateway {
+	return redisGateway{client: client, claimCheck: claimCheck}
+}
+
+func (g redisGateway) SupportsTransport(transport entities.StepTransport) bool {
+	return transport == entities.StepTransportRedis
+}
+
+func (g redisGateway) SendStepToExecute(
+	sagaName string,
+	sagaExecution entities.SagaExecution,
+	sagaStep entities.StepExecution,
+	stepsExecution []entities.StepExecution,
+	isCompensation bool,
+) error {
+	marshaledValue, err := marshalStepToExecute(
+		g.claimCheck, sagaName, sagaExecution, sagaStep, stepsExecution, isCompensation,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = g.client.XAdd(context.Background(), &redis.XAddArgs{
+		Stream: RedisStepStream(sagaName, sagaStep.Name),
+		Values: map[string]interface{}{RedisValueField: marshaledValue},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("error sending step to be executed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package step_execution
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+)
+
+type registry struct {
+	gateways map[entities.StepTransport]sagas.StepExecutionGateway
+}
+
+// NewRegistry routes every step to the gateway of its transport, so one saga can mix workers of different transports.
+func NewRegistry(gateways map[entities.StepTransport]sagas.StepExecutionGateway) sagas.StepExecutionGateway {
+	return registry{gateways: gateways}
+}
+
+func (r registry) SupportsTransport(transport entities.StepTransport) bool {
+	_, ok := r.gateways[transport]
+	return ok
+}
+
+func (r registry) SendStepToExecute(
+	sagaName string,
+	sagaExecution entities.SagaExecution,
+	sagaStep entities.StepExecution,
+	stepsExecution []entities.StepExecution,
+	isCompensation bool,
+) error {
+	gateway, ok := r.gateways[sagaStep.Transport]
+	if !ok {
+		return fmt.Errorf("%w: %s", sagas.ErrUnsupportedTransport, sagaStep.Transport)
+	}
+
+	return gateway.SendStepToExecute(sagaName, sagaExecution, sagaStep, stepsExecution, isCompensation)
+}
+
+// ValidateStep hands the step to the gateway of its transport, when the gateway validates its steps.
+func (r registry) ValidateStep(step entities.SagaStep) error {
+	validator, ok := r.gateways[step.Transport].(sagas.StepValidator)
+	if !ok {
+		return nil
+	}
+
+	return validator.ValidateStep(step)
+}
+
+// ProvisionSteps hands the steps of each transport to its gateway, when the gateway provisions its steps.
+func (r registry) ProvisionSteps(ctx context.Context, sagaName string, steps []entities.SagaStep) error {
+	stepsByTransport := make(map[entities.StepTransport][]entities.SagaStep)
+	for _, step := range steps {
+		stepsByTransport[step.Transport] = append(stepsByTransport[step.Transport], step)
+	}
+
+	for transport, transportSteps := range stepsByTransport {
+		provisioner, ok := r.gateways[transport].(sagas.StepProvisioner)
+		if !ok {
+			continue
+		}
+		if err := provisioner.ProvisionSteps(ctx, sagaName, transportSteps); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package step_execution
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+)
+
+// StepResult is the JSON result the workers send through every transport.
+type StepResult struct {
+	SagaName    string           `json:"saga_name"`
+	StepIndex   int              `json:"step_index"`
+	ExecutionID uuid.UUID        `json:"execution_id"`
+	Result      string           `json:"result"`
+	Output      json.RawMessage  `json:"output"`
+	Error       *StepResultError `json:"error,omitempty"`
+}
+
+// StepResultError is sent along with an `error` result to explain the failure
+type StepResultError struct {
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	Retryable bool            `json:"retryable"`
+	Details   json.RawMessage `json:"details,omitempty"`
+}
+
+// UnmarshalStepResult reads a JSON result to be handed to the saga service.
+func UnmarshalStepResult(value []byte) (sagas.StepResultVO, error) {
+	var result StepResult
+	if err := json.Unmarshal(value, &result); err != nil {
+		return sagas.StepResultVO{}, err
+	}
+
+	return result.toVO(), nil
+}
+
+// UnmarshalEncodedStepResult reads a result in the encoding of its content type, JSON or protobuf. Protobuf results
+// with an invalid execution id are read without it.
+func UnmarshalEncodedStepResult(contentType string, value []byte) (sagas.StepResultVO, error) {
+	if !IsProtobufContentType(contentType) {
+		return UnmarshalStepResult(value)
+	}
+
+	result, err := unmarshalProtoStepResult(value)
+	if err != nil {
+		return sagas.StepResultVO{}, err
+	}
+
+	return result.toVO(), nil
+}
+
+func (r StepResult) toVO() sagas.StepResultVO {
+	vo := sagas.StepResultVO{
+		SagaName:    r.SagaName,
+		StepIndex:   r.StepIndex,
+		ExecutionID: r.ExecutionID,
+		Result:      r.Result,
+		Output:      r.Output,
+	}
+	if r.Error != nil {
+		vo.Error = &entities.StepError{
+			Code:      r.Error.Code,
+			Message:   r.Error.Message,
+			Retryable: r.Error.Retryable,
+			Details:   r.Error.Details,
+		}
+	}
+
+	return vo
+}
@@ -0,0 +1,477 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.17.3
+// source: sukuna/step/v1/step.proto
+
+// Version 1 of the step messages, fields are only added to it, breaking changes go to a new version.
+
+package steppb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StepResult int32
+
+const (
+	StepResult_STEP_RESULT_UNSPECIFIED StepResult = 0
+	StepResult_STEP_RESULT_SUCCESS     StepResult = 1
+	StepResult_STEP_RESULT_ERROR       StepResult = 2
+	StepResult_STEP_RESULT_COMPENSATED StepResult = 3
+)
+
+// Enum value maps for StepResult.
+var (
+	StepResult_name = map[int32]string{
+		0: "STEP_RESULT_UNSPECIFIED",
+		1: "STEP_RESULT_SUCCESS",
+		2: "STEP_RESULT_ERROR",
+		3: "STEP_RESULT_COMPENSATED",
+	}
+	StepResult_value = map[string]int32{
+		"STEP_RESULT_UNSPECIFIED": 0,
+		"STEP_RESULT_SUCCESS":     1,
+		"STEP_RESULT_ERROR":       2,
+		"STEP_RESULT_COMPENSATED": 3,
+	}
+)
+
+func (x StepResult) Enum() *StepResult {
+	p := new(StepResult)
+	*p = x
+	return p
+}
+
+func (x StepResult) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StepResult) Descriptor() protoreflect.EnumDescriptor {
+	return file_sukuna_step_v1_step_proto_enumTypes[0].Descriptor()
+}
+
+func (StepResult) Type() protoreflect.EnumType {
+	return &file_sukuna_step_v1_step_proto_enumTypes[0]
+}
+
+func (x StepResult) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StepResult.Descriptor instead.
+func (StepResult) EnumDescriptor() ([]byte, []int) {
+	return file_sukuna_step_v1_step_proto_rawDescGZIP(), []int{0}
+}
+
+// StepToExecute is the command sent to the workers to execute or compensate a step.
+type StepToExecute struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SagaName    string `protobuf:"bytes,1,opt,name=saga_name,json=sagaName,proto3" json:"saga_name,omitempty"`
+	StepIndex   int32  `protobuf:"varint,2,opt,name=step_index,json=stepIndex,proto3" json:"step_index,omitempty"`
+	ExecutionId string `protobuf:"bytes,3,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	// payload is the JSON payload of the step, empty when it's in the payload store
+	Payload          []byte `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
+	PayloadReference string `protobuf:"bytes,5,opt,name=payload_reference,json=payloadReference,proto3" json:"payload_reference,omitempty"`
+	IsCompensation   bool   `protobuf:"varint,6,opt,name=is_compensation,json=isCompensation,proto3" json:"is_compensation,omitempty"`
+}
+
+func (x *StepToExecute) Reset() {
+	*x = StepToExecute{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sukuna_step_v1_step_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StepToExecute) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepToExecute) ProtoMessage() {}
+
+func (x *StepToExecute) ProtoReflect() protoreflect.Message {
+	mi := &file_sukuna_step_v1_step_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepToExecute.ProtoReflect.Descriptor instead.
+func (*StepToExecute) Descriptor() ([]byte, []int) {
+	return file_sukuna_step_v1_step_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StepToExecute) GetSagaName() string {
+	if x != nil {
+		return x.SagaName
+	}
+	return ""
+}
+
+func (x *StepToExecute) GetStepIndex() int32 {
+	if x != nil {
+		return x.StepIndex
+	}
+	return 0
+}
+
+func (x *StepToExecute) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+func (x *StepToExecute) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *StepToExecute) GetPayloadReference() string {
+	if x != nil {
+		return x.PayloadReference
+	}
+	return ""
+}
+
+func (x *StepToExecute) GetIsCompensation() bool {
+	if x != nil {
+		return x.IsCompensation
+	}
+	return false
+}
+
+// SagaStepResult is sent back by the workers once the step is executed or compensated.
+type SagaStepResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SagaName    string     `protobuf:"bytes,1,opt,name=saga_name,json=sagaName,proto3" json:"saga_name,omitempty"`
+	StepIndex   int32      `protobuf:"varint,2,opt,name=step_index,json=stepIndex,proto3" json:"step_index,omitempty"`
+	ExecutionId string     `protobuf:"bytes,3,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	Result      StepResult `protobuf:"varint,4,opt,name=result,proto3,enum=sukuna.step.v1.StepResult" json:"result,omitempty"`
+	// output is the JSON output of the step
+	Output []byte     `protobuf:"bytes,5,opt,name=output,proto3" json:"output,omitempty"`
+	Error  *StepError `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *SagaStepResult) Reset() {
+	*x = SagaStepResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sukuna_step_v1_step_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SagaStepResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SagaStepResult) ProtoMessage() {}
+
+func (x *SagaStepResult) ProtoReflect() protoreflect.Message {
+	mi := &file_sukuna_step_v1_step_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SagaStepResult.ProtoReflect.Descriptor instead.
+func (*SagaStepResult) Descriptor() ([]byte, []int) {
+	return file_sukuna_step_v1_step_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SagaStepResult) GetSagaName() string {
+	if x != nil {
+		return x.SagaName
+	}
+	return ""
+}
+
+func (x *SagaStepResult) GetStepIndex() int32 {
+	if x != nil {
+		return x.StepIndex
+	}
+	return 0
+}
+
+func (x *SagaStepResult) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+func (x *SagaStepResult) GetResult() StepResult {
+	if x != nil {
+		return x.Result
+	}
+	return StepResult_STEP_RESULT_UNSPECIFIED
+}
+
+func (x *SagaStepResult) GetOutput() []byte {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *SagaStepResult) GetError() *StepError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+// StepError is sent along with an error result to explain the failure.
+type StepError struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Code      string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message   string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Retryable bool   `protobuf:"varint,3,opt,name=retryable,proto3" json:"retryable,omitempty"`
+	// details is a JSON document
+	Details []byte `protobuf:"bytes,4,opt,name=details,proto3" json:"details,omitempty"`
+}
+
+func (x *StepError) Reset() {
+	*x = StepError{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sukuna_step_v1_step_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StepError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepError) ProtoMessage() {}
+
+func (x *StepError) ProtoReflect() protoreflect.Message {
+	mi := &file_sukuna_step_v1_step_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepError.ProtoReflect.Descriptor instead.
+func (*StepError) Descriptor() ([]byte, []int) {
+	return file_sukuna_step_v1_step_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StepError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *StepError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *StepError) GetRetryable() bool {
+	if x != nil {
+		return x.Retryable
+	}
+	return false
+}
+
+func (x *StepError) GetDetails() []byte {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+var File_sukuna_step_v1_step_proto protoreflect.FileDescriptor
+
+var file_sukuna_step_v1_step_proto_rawDesc = []byte{
+	0x0a, 0x19, 0x73, 0x75, 0x6b, 0x75, 0x6e, 0x61, 0x2f, 0x73, 0x74, 0x65, 0x70, 0x2f, 0x76, 0x31,
+	0x2f, 0x73, 0x74, 0x65, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x73, 0x75, 0x6b,
+	0x75, 0x6e, 0x61, 0x2e, 0x73, 0x74, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x22, 0xde, 0x01, 0x0a, 0x0d,
+	0x53, 0x74, 0x65, 0x70, 0x54, 0x6f, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x12, 0x1b, 0x0a,
+	0x09, 0x73, 0x61, 0x67, 0x61, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x73, 0x61, 0x67, 0x61, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74,
+	0x65, 0x70, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09,
+	0x73, 0x74, 0x65, 0x70, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x21, 0x0a, 0x0c, 0x65, 0x78, 0x65,
+	0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07,
+	0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70,
+	0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x2b, 0x0a, 0x11, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61,
+	0x64, 0x5f, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x10, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65,
+	0x6e, 0x63, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x73, 0x5f, 0x63, 0x6f, 0x6d, 0x70, 0x65, 0x6e,
+	0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x69, 0x73,
+	0x43, 0x6f, 0x6d, 0x70, 0x65, 0x6e, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xec, 0x01, 0x0a,
+	0x0e, 0x53, 0x61, 0x67, 0x61, 0x53, 0x74, 0x65, 0x70, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12,
+	0x1b, 0x0a, 0x09, 0x73, 0x61, 0x67, 0x61, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x73, 0x61, 0x67, 0x61, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a,
+	0x73, 0x74, 0x65, 0x70, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x09, 0x73, 0x74, 0x65, 0x70, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x21, 0x0a, 0x0c, 0x65,
+	0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x32,
+	0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a,
+	0x2e, 0x73, 0x75, 0x6b, 0x75, 0x6e, 0x61, 0x2e, 0x73, 0x74, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x74, 0x65, 0x70, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x2f, 0x0a, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x73, 0x75, 0x6b, 0x75,
+	0x6e, 0x61, 0x2e, 0x73, 0x74, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x65, 0x70, 0x45,
+	0x72, 0x72, 0x6f, 0x72, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x71, 0x0a, 0x09, 0x53,
+	0x74, 0x65, 0x70, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x74, 0x72, 0x79, 0x61,
+	0x62, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x72, 0x65, 0x74, 0x72, 0x79,
+	0x61, 0x62, 0x6c, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x2a, 0x76,
+	0x0a, 0x0a, 0x53, 0x74, 0x65, 0x70, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1b, 0x0a, 0x17,
+	0x53, 0x54, 0x45, 0x50, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x5f, 0x55, 0x4e, 0x53, 0x50,
+	0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x17, 0x0a, 0x13, 0x53, 0x54, 0x45,
+	0x50, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x5f, 0x53, 0x55, 0x43, 0x43, 0x45, 0x53, 0x53,
+	0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x53, 0x54, 0x45, 0x50, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c,
+	0x54, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x02, 0x12, 0x1b, 0x0a, 0x17, 0x53, 0x54, 0x45,
+	0x50, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x45, 0x4e, 0x53,
+	0x41, 0x54, 0x45, 0x44, 0x10, 0x03, 0x42, 0x42, 0x5a, 0x40, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x68, 0x65, 0x70, 0x61, 0x62, 0x6c, 0x6f, 0x61, 0x67, 0x75,
+	0x69, 0x6c, 0x61, 0x72, 0x2f, 0x73, 0x75, 0x6b, 0x75, 0x6e, 0x61, 0x2f, 0x67, 0x61, 0x74, 0x65,
+	0x77, 0x61, 0x79, 0x73, 0x2f, 0x73, 0x74, 0x65, 0x70, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74,
+	0x69, 0x6f, 0x6e, 0x2f, 0x73, 0x74, 0x65, 0x70, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_sukuna_step_v1_step_proto_rawDescOnce sync.Once
+	file_sukuna_step_v1_step_proto_rawDescData = file_sukuna_step_v1_step_proto_rawDesc
+)
+
+func file_sukuna_step_v1_step_proto_rawDescGZIP() []byte {
+	file_sukuna_step_v1_step_proto_rawDescOnce.Do(func() {
+		file_sukuna_step_v1_step_proto_rawDescData = protoimpl.X.CompressGZIP(file_sukuna_step_v1_step_proto_rawDescData)
+	})
+	return file_sukuna_step_v1_step_proto_rawDescData
+}
+
+var file_sukuna_step_v1_step_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_sukuna_step_v1_step_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_sukuna_step_v1_step_proto_goTypes = []interface{}{
+	(StepResult)(0),        // 0: sukuna.step.v1.StepResult
+	(*StepToExecute)(nil),  // 1: sukuna.step.v1.StepToExecute
+	(*SagaStepResult)(nil), // 2: sukuna.step.v1.SagaStepResult
+	(*StepError)(nil),      // 3: sukuna.step.v1.StepError
+}
+var file_sukuna_step_v1_step_proto_depIdxs = []int32{
+	0, // 0: sukuna.step.v1.SagaStepResult.result:type_name -> sukuna.step.v1.StepResult
+	3, // 1: sukuna.step.v1.SagaStepResult.error:type_name -> sukuna.step.v1.StepError
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_sukuna_step_v1_step_proto_init() }
+func file_sukuna_step_v1_step_proto_init() {
+	if File_sukuna_step_v1_step_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_sukuna_step_v1_step_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StepToExecute); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sukuna_step_v1_step_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SagaStepResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sukuna_step_v1_step_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StepError); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_sukuna_step_v1_step_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_sukuna_step_v1_step_proto_goTypes,
+		DependencyIndexes: file_sukuna_step_v1_step_proto_depIdxs,
+		EnumInfos:         file_sukuna_step_v1_step_proto_enumTypes,
+		MessageInfos:      file_sukuna_step_v1_step_proto_msgTypes,
+	}.Build()
+	File_sukuna_step_v1_step_proto = out.File
+	file_sukuna_step_v1_step_proto_rawDesc = nil
+	file_sukuna_step_v1_step_proto_goTypes = nil
+	file_sukuna_step_v1_step_proto_depIdxs = nil
+}
@@ -0,0 +1,58 @@
+package step_execution
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thepabloaguilar/sukuna/core/entities"
+	"github.com/thepabloaguilar/sukuna/core/sagas"
+	"github.com/thepabloaguilar/sukuna/core/tasks"
+	"github.com/thepabloaguilar/sukuna/gateways/payload_store"
+)
+
+// TaskQueue is the queue the workers of a step poll, it has the same name as the Kafka topic.
+func TaskQueue(sagaName, stepName string) string {
+	return fmt.Sprintf("%s-%s", sagaName, stepName)
+}
+
+type taskGateway struct {
+	repository tasks.Repository
+	claimCheck payload_store.ClaimCheck
+}
+
+// NewTaskGateway keeps the steps as tasks, the workers pull them from the API instead of consuming a broker.
+func NewTaskGateway(repository tasks.Repository, claimCheck payload_store.ClaimCheck) sagas.StepExecutionGateway {
+	return taskGateway{repository: repository, claimCheck: claimCheck}
+}
+
+func (g taskGateway) SupportsTransport(transport entities.StepTransport) bool {
+	return transport == entities.StepTransportPostgres
+}
+
+func (g taskGateway) SendStepToExecute(
+	sagaName string,
+	sagaExecution entities.SagaExecution,
+	sagaStep entities.StepExecution,
+	stepsExecution []entities.StepExecution,
+	isCompensation bool,
+) error {
+	marshaledValue, err := marshalStepToExecute(
+		g.claimCheck, sagaName, sagaExecution, sagaStep, stepsExecution, isCompensation,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = g.repository.CreateTask(context.Background(), entities.Task{
+		Queue:           TaskQueue(sagaName, sagaStep.Name),
+		SagaName:        sagaName,
+		SagaExecutionID: sagaExecution.SagaExecutionID,
+		StepIndex:       sagaStep.Index,
+		Step:            marshaledValue,
+	})
+	if err != nil {
+		return fmt.Errorf("error sending step to be executed: %w", err)
+	}
+
+	return nil
+}
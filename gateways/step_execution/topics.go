@@ -0,0 +1,119 @@
+package step_execution
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Shopify/sarama"
+)
+
+const (
+	// KafkaResultTopic is the topic the workers send the step results to, before the naming prefixes.
+	KafkaResultTopic        = "sukuna-out"
+	compensationTopicSuffix = "compensation"
+)
+
+// maxTopicNameLength is the longest topic name Kafka accepts
+const maxTopicNameLength = 249
+
+var (
+	ErrInvalidTopicName = errors.New("invalid kafka topic name")
+
+	topicNamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+)
+
+// validateTopicName checks a topic override is a name Kafka accepts, an empty name means no override.
+func validateTopicName(topic string) error {
+	if topic == "" {
+		return nil
+	}
+	if len(topic) > maxTopicNameLength || topic == "." || topic == ".." || !topicNamePattern.MatchString(topic) {
+		return fmt.Errorf("%w: %q", ErrInvalidTopicName, topic)
+	}
+
+	return nil
+}
+
+// TopicNaming builds the names of the Kafka topics, `[prefix-][environment-]<saga>-<step>` for the steps.
+// The topic of a step may be overridden by the `topic` and `compensation_topic` of its transport config,
+// which are used as they are.
+type TopicNaming struct {
+	Prefix      string
+	Environment string
+	// SeparateCompensations sends the compensations to the `<step topic>-compensation` topic
+	SeparateCompensations bool
+}
+
+func (n TopicNaming) ResultTopic() string {
+	return n.name(KafkaResultTopic)
+}
+
+// stepTopic is the topic the step is sent to, to be executed or compensated.
+func (n TopicNaming) stepTopic(sagaName string, stepName string, config kafkaStepConfig, isCompensation bool) string {
+	topic := config.Topic
+	if topic == "" {
+		topic = n.name(sagaName, stepName)
+	}
+	if !isCompensation || !n.SeparateCompensations {
+		return topic
+	}
+
+	if config.CompensationTopic != "" {
+		return config.CompensationTopic
+	}
+	return fmt.Sprintf("%s-%s", topic, compensationTopicSuffix)
+}
+
+// stepTopics are all the topics of the step, its compensation one included when compensations are separated.
+func (n TopicNaming) stepTopics(sagaName string, stepName string, config kafkaStepConfig) []string {
+	topics := []string{n.stepTopic(sagaName, stepName, config, false)}
+	if n.SeparateCompensations {
+		topics = append(topics, n.stepTopic(sagaName, stepName, config, true))
+	}
+
+	return topics
+}
+
+func (n TopicNaming) name(parts ...string) string {
+	names := make([]string, 0, len(parts)+2)
+	if n.Prefix != "" {
+		names = append(names, n.Prefix)
+	}
+	if n.Environment != "" {
+		names = append(names, n.Environment)
+	}
+
+	return strings.Join(append(names, parts...), "-")
+}
+
+// TopicProvisioner creates the Kafka topics, the ones that already exist are left as they are.
+type TopicProvisioner struct {
+	admin  sarama.ClusterAdmin
+	detail sarama.TopicDetail
+}
+
+func NewTopicProvisioner(admin sarama.ClusterAdmin, partitions int32, replicationFactor int16) *TopicProvisioner {
+	return &TopicProvisioner{
+		admin:  admin,
+		detail: sarama.TopicDetail{NumPartitions: partitions, ReplicationFactor: replicationFactor},
+	}
+}
+
+func (p *TopicProvisioner) CreateTopics(topics ...string) error {
+	for _, topic := range topics {
+		detail := p.detail
+		err := p.admin.CreateTopic(topic, &detail, false)
+
+		var topicErr *sarama.TopicError
+		if errors.As(err, &topicErr) && topicErr.Err == sarama.ErrTopicAlreadyExists {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error creating the %s topic: %w", topic, err)
+		}
+	}
+
+	return nil
+}
@@ -3,11 +3,12 @@ module github.com/thepabloaguilar/sukuna
 go 1.16
 
 require (
-	github.com/Shopify/sarama v1.29.1
+	github.com/Shopify/sarama v1.37.0
 	github.com/containerd/containerd v1.5.5 // indirect
 	github.com/docker/docker v20.10.8+incompatible // indirect
 	github.com/docker/go-connections v0.4.0 // indirect
 	github.com/go-playground/validator/v10 v10.9.0
+	github.com/go-redis/redis/v8 v8.11.3
 	github.com/gofiber/fiber/v2 v2.17.0
 	github.com/golang-migrate/migrate v3.5.4+incompatible
 	github.com/google/uuid v1.3.0
@@ -15,6 +16,11 @@ require (
 	github.com/jackc/pgtype v1.8.1
 	github.com/jackc/pgx/v4 v4.13.0
 	github.com/kyleconroy/sqlc v1.9.0
+	github.com/minio/minio-go/v7 v7.0.12
+	github.com/nats-io/nats.go v1.11.0
+	github.com/qri-io/jsonpointer v0.1.1
 	github.com/qri-io/jsonschema v0.2.1
-	github.com/wagslane/go-rabbitmq v0.6.2 // indirect
+	github.com/streadway/amqp v1.0.0
+	github.com/wagslane/go-rabbitmq v0.6.2
+	google.golang.org/protobuf v1.28.1
 )